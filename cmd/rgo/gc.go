@@ -0,0 +1,43 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/generator"
+	"github.com/urfave/cli/v2"
+)
+
+func GC(ctx *cli.Context) error {
+	if err := InitConfig(); err != nil {
+		return err
+	}
+
+	err := generator.ReconcileCache(rgoBasePath, c)
+	if err != nil {
+		return err
+	}
+
+	removed, err := generator.PruneCache(rgoBasePath, c, ctx.Duration(consts.MaxAgeFlag), ctx.Int64(consts.MaxSizeFlag)*1024*1024)
+	for _, path := range removed {
+		fmt.Println("removed", path)
+	}
+
+	return err
+}
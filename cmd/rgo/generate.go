@@ -29,6 +29,7 @@ import (
 
 	"github.com/cloudwego-contrib/rgo/pkg/config"
 	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/generator"
 
 	"github.com/cloudwego-contrib/rgo/pkg/utils"
 	"github.com/cloudwego/kitex/tool/cmd/kitex/sdk"
@@ -48,13 +49,33 @@ var (
 )
 
 func InitConfig() error {
+	var root string
 	var err error
 
-	currentPath, err = utils.GetProjectHashPathWithUnderline()
-	if err != nil {
-		panic(err)
+	// An explicit config decides the project, wherever rgo is run from.
+	if idlConfigPath == "" {
+		root, err = utils.GetProjectRoot()
+		if err != nil {
+			return fmt.Errorf("failed to find project root: %v", err)
+		}
+		idlConfigPath = filepath.Join(root, consts.RGOConfigFile)
+	} else {
+		if idlConfigPath, err = filepath.Abs(idlConfigPath); err != nil {
+			return err
+		}
+		root, err = utils.FindProjectRoot(filepath.Dir(idlConfigPath))
+		if err != nil {
+			return fmt.Errorf("failed to find project root: %v", err)
+		}
+	}
+
+	// Work in the project root, where go.work and the modules are, wherever rgo is run.
+	if err = os.Chdir(root); err != nil {
+		return fmt.Errorf("failed to change directory to project root %s: %v", root, err)
 	}
 
+	currentPath = utils.ProjectHashPathWithUnderline(root)
+
 	rgoBasePath = filepath.Join(utils.GetDefaultUserPath(), consts.RGOBasePath, currentPath)
 
 	c, err = config.ReadConfig(idlConfigPath)
@@ -106,6 +127,8 @@ func GenerateRGOCode() error {
 		}
 	}
 
+	report := generator.NewGenerationReport()
+
 	for _, repo := range c.IDLRepos {
 		buildPath := filepath.Join(rgoBasePath, consts.BuildPath, repo.RepoName, repo.Commit)
 
@@ -133,30 +156,41 @@ func GenerateRGOCode() error {
 						fmt.Sprintf("--%s", consts.IDLPathFlag), idlPath,
 					}
 
+					args = append(args, generator.IDLPluginArgs(idl)...)
+
 					for _, customArg := range kitexCustomArgs.Value() {
 						args = append(args, fmt.Sprintf("--%s", consts.KitexArgsFlag), customArg)
 					}
 
 					cmd := exec.Command("rgo", args...)
 
-					if err = cmd.Run(); err != nil {
-						return fmt.Errorf("error generate rgo kitex_gen code: %v", err)
+					if err := cmd.Run(); err != nil {
+						err = fmt.Errorf("error generate rgo kitex_gen code: %v", err)
+						report.AddServiceResult(idl.ServiceName, path, err)
+						return err
 					}
 
+					report.AddServiceResult(idl.ServiceName, path, nil)
+
 					if isGoPackagesDriver {
-						err = utils.AddModuleToGoWork(path)
-						if err != nil {
+						if err := utils.AddModuleToGoWork(path); err != nil {
 							return err
 						}
 					} else {
 						oldPath := filepath.Join(rgoBasePath, consts.RepoPath, idl.FormatServiceName)
 
-						err = utils.ReplaceModulesInGoWork(oldPath, path)
-						if err != nil {
+						if err := utils.ReplaceModulesInGoWork(oldPath, path); err != nil {
 							return err
 						}
 					}
 
+					generator.RunHooks(c.Hooks.PostService, generator.HookContext{
+						Stage:       consts.HookStagePostService,
+						ServiceName: idl.ServiceName,
+						ModulePath:  module,
+						OutputDir:   path,
+					}, report)
+
 					return nil
 				})
 			}
@@ -165,9 +199,15 @@ func GenerateRGOCode() error {
 
 	if err := g.Wait(); err != nil {
 		return err
-	} else {
-		return utils.RunGoWorkSync()
 	}
+
+	if err := utils.RunGoWorkSync(); err != nil {
+		return err
+	}
+
+	generator.RunHooks(c.Hooks.PostRun, generator.HookContext{Stage: consts.HookStagePostRun}, report)
+
+	return report.Err()
 }
 
 func generateKitexGen(wd, module, idlPath string, customArgs []string, plugins ...plugin.SDKPlugin) error {
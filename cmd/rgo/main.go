@@ -56,7 +56,7 @@ func Init() *cli.App {
 			Name:  GenerateName,
 			Usage: GenerateUsage,
 			Flags: []cli.Flag{
-				&cli.StringFlag{Name: consts.ConfigFlag, Aliases: []string{"c"}, Usage: "rgo_config file path, default: ./rgo_config.yaml", Destination: &idlConfigPath, Value: consts.RGOConfigPath},
+				&cli.StringFlag{Name: consts.ConfigFlag, Aliases: []string{"c"}, Usage: "rgo_config file path, default: rgo_config.yaml of the project root", Destination: &idlConfigPath},
 				&cli.StringSliceFlag{Name: consts.KitexArgsFlag, Aliases: []string{"k"}, Usage: "kitex custom args", Destination: &kitexCustomArgs},
 			},
 			Action: func(c *cli.Context) error {
@@ -67,12 +67,30 @@ func Init() *cli.App {
 			Name:  CleanName,
 			Usage: CleanUsage,
 			Flags: []cli.Flag{
-				&cli.StringFlag{Name: consts.ConfigFlag, Aliases: []string{"c"}, Usage: "rgo_config file path, default: ./rgo_config.yaml", Destination: &idlConfigPath, Value: consts.RGOConfigPath},
+				&cli.StringFlag{Name: consts.ConfigFlag, Aliases: []string{"c"}, Usage: "rgo_config file path, default: rgo_config.yaml of the project root", Destination: &idlConfigPath},
 			},
 			Action: func(c *cli.Context) error {
 				return Clean()
 			},
 		},
+		{
+			Name:  GCName,
+			Usage: GCUsage,
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: consts.ConfigFlag, Aliases: []string{"c"}, Usage: "rgo_config file path, default: rgo_config.yaml of the project root", Destination: &idlConfigPath},
+				&cli.DurationFlag{Name: consts.MaxAgeFlag, Usage: "remove unused build commits older than this, e.g. 720h"},
+				&cli.Int64Flag{Name: consts.MaxSizeFlag, Usage: "remove unused build commits, oldest first, until the cache is smaller than this many MB"},
+			},
+			Action: GC,
+		},
+		{
+			Name:  VerifyAPIName,
+			Usage: VerifyAPIUsage,
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: consts.ConfigFlag, Aliases: []string{"c"}, Usage: "rgo_config file path, default: rgo_config.yaml of the project root", Destination: &idlConfigPath},
+			},
+			Action: VerifyAPI,
+		},
 		{
 			Name:  InitName,
 			Usage: InitUsage,
@@ -84,34 +102,53 @@ func Init() *cli.App {
 		{
 			Name:  ThriftgoName,
 			Usage: ThriftgoUsage,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.StringFlag{Name: consts.PwdFlag, Aliases: []string{"p"}, Usage: "rgo kitex pwd"},
 				&cli.StringFlag{Name: consts.ModuleFlag, Aliases: []string{"m"}, Usage: "rgo kitex module"},
 				&cli.StringFlag{Name: consts.ServiceNameFlag, Aliases: []string{"s"}, Usage: "rgo kitex service_name"},
 				&cli.StringFlag{Name: consts.FormatServiceNameFlag, Aliases: []string{"fs"}, Usage: "rgo kitex format_service_name"},
 				&cli.StringFlag{Name: consts.IDLPathFlag, Aliases: []string{"i"}, Usage: "rgo kitex idl_path"},
+				&cli.BoolFlag{Name: consts.MockFlag, Usage: "also generate a mock client"},
+				&cli.BoolFlag{Name: consts.ServerFlag, Usage: "also generate a handler interface and NewServer"},
+				&cli.StringFlag{Name: consts.ProtocolFlag, Usage: "protocol of the generated client, http generates a client of the routes of the api annotations"},
 				&cli.StringSliceFlag{Name: consts.ThriftgoCustomArgsFlag, Aliases: []string{"t"}, Usage: "thriftgo custom args"},
-			},
+			}, clientFlags()...),
 			Action: RunThriftgoCommand,
 		},
 		{
 			Name:  KitexName,
 			Usage: KitexUsage,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.StringFlag{Name: consts.PluginTypeFlag, Aliases: []string{"t"}, Usage: "rgo plugin type"},
 				&cli.StringFlag{Name: consts.PwdFlag, Aliases: []string{"pp"}, Usage: "rgo kitex pwd", Value: "."},
 				&cli.StringFlag{Name: consts.ModuleFlag, Aliases: []string{"m"}, Usage: "rgo kitex module"},
 				&cli.StringFlag{Name: consts.ServiceNameFlag, Aliases: []string{"s"}, Usage: "rgo kitex service_name"},
 				&cli.StringFlag{Name: consts.FormatServiceNameFlag, Aliases: []string{"fs"}, Usage: "rgo kitex format_service_name"},
 				&cli.StringFlag{Name: consts.IDLPathFlag, Aliases: []string{"i"}, Usage: "rgo kitex idl_path"},
+				&cli.BoolFlag{Name: consts.MockFlag, Usage: "also generate a mock client"},
+				&cli.BoolFlag{Name: consts.ServerFlag, Usage: "also generate a handler interface and NewServer"},
+				&cli.StringFlag{Name: consts.IDLModeFlag, Usage: "rgo kitex idl mode, generic generates a generic-call client without kitex_gen"},
+				&cli.StringFlag{Name: consts.ProtocolFlag, Usage: "protocol of the generated client, http generates a client of the routes of the api annotations"},
 				&cli.StringSliceFlag{Name: consts.KitexArgsFlag, Aliases: []string{"k"}, Usage: "Kitex custom args"},
-			},
+			}, clientFlags()...),
 			Action: RunKitexCommand,
 		},
 	}
 	return app
 }
 
+// clientFlags are the options of the default client compiled into the generated code.
+func clientFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{Name: consts.ClientHostPortsFlag, Usage: "default client hostports"},
+		&cli.StringFlag{Name: consts.ClientRPCTimeoutFlag, Usage: "default client rpc timeout, e.g. 1s"},
+		&cli.StringFlag{Name: consts.ClientConnectTimeoutFlag, Usage: "default client connect timeout, e.g. 50ms"},
+		&cli.StringFlag{Name: consts.ClientTransportFlag, Usage: "default client transport protocol, e.g. ttheader"},
+		&cli.StringFlag{Name: consts.ClientResolverFlag, Usage: "default client resolver, e.g. dns"},
+		&cli.StringSliceFlag{Name: consts.MiddlewaresFlag, Usage: "client middlewares, in chain order, e.g. github.com/acme/mw.Auth"},
+	}
+}
+
 const (
 	AppUsage = "generate or clean rpc code for rgo"
 
@@ -129,6 +166,23 @@ Examples:
 Examples:
   # Clean rgo code 
   rgo clean
+`
+	GCName  = "gc"
+	GCUsage = `remove stale rgo caches
+
+Examples:
+  # Remove caches of removed idls and orphaned projects
+  rgo gc
+
+  # Also remove unused build commits older than 30 days, and keep the cache under 2GB
+  rgo gc --max_age 720h --max_size 2048
+`
+	VerifyAPIName  = "verify-api"
+	VerifyAPIUsage = `check that the edit-period and build-period clients have the same API
+
+Examples:
+  # Compare the clients generated by the IDE and by rgo generate
+  rgo verify-api
 `
 	InitName  = "init_config"
 	InitUsage = `init rgo project config
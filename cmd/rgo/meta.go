@@ -16,7 +16,9 @@
 
 package main
 
+import "github.com/cloudwego-contrib/rgo/pkg/consts"
+
 const (
 	Name    = "rgo"
-	Version = "0.0.1"
+	Version = consts.RGOVersion
 )
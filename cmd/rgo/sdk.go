@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 
+	"github.com/cloudwego-contrib/rgo/pkg/config"
 	"github.com/cloudwego-contrib/rgo/pkg/consts"
 	"github.com/cloudwego-contrib/rgo/pkg/generator/plugin"
 	plugin2 "github.com/cloudwego/thriftgo/plugin"
@@ -32,6 +33,13 @@ func RunThriftgoCommand(c *cli.Context) error {
 	serviceName := c.String(consts.ServiceNameFlag)
 	formatServiceName := c.String(consts.FormatServiceNameFlag)
 	pluginType := c.String(consts.PluginTypeFlag)
+	mock := c.Bool(consts.MockFlag)
+	server := c.Bool(consts.ServerFlag)
+	clientConfig := clientConfigFromFlags(c)
+	middlewares, err := middlewaresFromFlags(c)
+	if err != nil {
+		return err
+	}
 	thriftgoCustomArgs := c.StringSlice(consts.ThriftgoCustomArgsFlag)
 
 	if pluginType == "" {
@@ -40,10 +48,11 @@ func RunThriftgoCommand(c *cli.Context) error {
 			return err
 		}
 	} else {
-		rgoPlugin, err := plugin.GetRGOPlugin(pluginType, pwd, module, serviceName, formatServiceName)
+		rgoPlugin, err := plugin.GetRGOPlugin(pluginType, pwd, module, serviceName, formatServiceName, mock, server, clientConfig, middlewares)
 		if err != nil {
 			return err
 		}
+		rgoPlugin.HTTP = c.String(consts.ProtocolFlag) == consts.IDLProtocolHTTP
 		err = sdk.RunThriftgoAsSDK(pwd, []plugin2.SDKPlugin{rgoPlugin}, thriftgoCustomArgs...)
 		if err != nil {
 			return err
@@ -61,16 +70,37 @@ func RunKitexCommand(c *cli.Context) error {
 	formatServiceName := c.String(consts.FormatServiceNameFlag)
 	idlPath := c.String(consts.IDLPathFlag)
 	pluginType := c.String(consts.PluginTypeFlag)
+	mock := c.Bool(consts.MockFlag)
+	server := c.Bool(consts.ServerFlag)
+	clientConfig := clientConfigFromFlags(c)
+	middlewares, err := middlewaresFromFlags(c)
+	if err != nil {
+		return err
+	}
+	idlMode := c.String(consts.IDLModeFlag)
 	kitexCustomArgs := c.StringSlice(consts.KitexArgsFlag)
 
-	var rgoPlugin plugin2.SDKPlugin
-	var err error
-
-	rgoPlugin, err = plugin.GetRGOPlugin(pluginType, pwd, module, serviceName, formatServiceName)
+	rgoPlugin, err := plugin.GetRGOPlugin(pluginType, pwd, module, serviceName, formatServiceName, mock, server, clientConfig, middlewares)
 	if err != nil {
 		return err
 	}
 
+	if c.String(consts.ProtocolFlag) == consts.IDLProtocolHTTP {
+		err = plugin.GenerateHTTPClient(rgoPlugin, idlPath, kitexCustomArgs)
+		if err != nil {
+			return fmt.Errorf("failed to generate rgo http code:%v", err)
+		}
+		return nil
+	}
+
+	if idlMode == consts.IDLModeGeneric {
+		err = plugin.GenerateGenericClient(rgoPlugin, idlPath)
+		if err != nil {
+			return fmt.Errorf("failed to generate rgo generic code:%v", err)
+		}
+		return nil
+	}
+
 	err = generateKitexGen(pwd, module, idlPath, kitexCustomArgs, rgoPlugin)
 	if err != nil {
 		return fmt.Errorf("failed to generate rgo code:%v", err)
@@ -78,3 +108,25 @@ func RunKitexCommand(c *cli.Context) error {
 
 	return nil
 }
+
+func middlewaresFromFlags(c *cli.Context) ([]config.Middleware, error) {
+	var middlewares []config.Middleware
+	for _, ref := range c.StringSlice(consts.MiddlewaresFlag) {
+		m, err := config.ParseMiddlewareRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		middlewares = append(middlewares, m)
+	}
+	return middlewares, nil
+}
+
+func clientConfigFromFlags(c *cli.Context) config.Client {
+	return config.Client{
+		HostPorts:      c.StringSlice(consts.ClientHostPortsFlag),
+		RPCTimeout:     c.String(consts.ClientRPCTimeoutFlag),
+		ConnectTimeout: c.String(consts.ClientConnectTimeoutFlag),
+		Transport:      c.String(consts.ClientTransportFlag),
+		Resolver:       c.String(consts.ClientResolverFlag),
+	}
+}
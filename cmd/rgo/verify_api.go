@@ -0,0 +1,55 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/cloudwego-contrib/rgo/pkg/generator"
+	"github.com/urfave/cli/v2"
+)
+
+func VerifyAPI(ctx *cli.Context) error {
+	if err := InitConfig(); err != nil {
+		return err
+	}
+
+	results, err := generator.VerifyAPI(rgoBasePath, c)
+	if err != nil {
+		return err
+	}
+
+	mismatches := 0
+	for _, r := range results {
+		if len(r.Diff) == 0 {
+			fmt.Printf("%s: ok\n", r.ServiceName)
+			continue
+		}
+
+		mismatches++
+		fmt.Printf("%s: the edit-period (-) and build-period (+) APIs differ\n", r.ServiceName)
+		for _, line := range r.Diff {
+			fmt.Println("  " + line)
+		}
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("the API of %d services differs between the edit and build periods", mismatches)
+	}
+
+	return nil
+}
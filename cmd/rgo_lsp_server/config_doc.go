@@ -0,0 +1,397 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TobiasYin/go-lsp/lsp/defines"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/utils"
+)
+
+// configDocument is an rgo_config.yaml opened in the editor, with the
+// positions of the repos and IDLs it declares.
+type configDocument struct {
+	lines []string
+	// idlRoot is the directory the IDL repos of the project are cloned to.
+	idlRoot string
+	repos   []*configRepo
+	idls    []*configIDL
+	// err is the error parsing the document, if any.
+	err error
+}
+
+// configField is a scalar of the document, at a 0-based line and byte
+// column. Ranges sent to the client are converted to UTF-16 columns.
+type configField struct {
+	value     string
+	line, col int
+}
+
+// configItem spans the lines of an item of idl_repos or idls, end excluded.
+type configItem struct {
+	line, end int
+}
+
+type configRepo struct {
+	configItem
+	name, gitURL, branch, commit configField
+}
+
+type configIDL struct {
+	configItem
+	repoName, idlPath, serviceName configField
+}
+
+var (
+	yamlErrLine = regexp.MustCompile(`line (\d+)`)
+	// completionKey matches the line of a key of an IDL up to the cursor.
+	completionKey = regexp.MustCompile(`^\s*(?:-\s+)?(repo_name|idl_path)\s*:\s*(\S*)$`)
+)
+
+func parseConfigDocument(text, idlRoot string) *configDocument {
+	d := &configDocument{lines: strings.Split(text, "\n"), idlRoot: idlRoot}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &root); err != nil {
+		d.err = err
+		return d
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return d
+	}
+
+	top := root.Content[0].Content
+	for i := 0; i+1 < len(top); i += 2 {
+		if top[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+
+		sectionEnd := len(d.lines)
+		if i+2 < len(top) {
+			sectionEnd = top[i+2].Line - 1
+		}
+
+		items := top[i+1].Content
+		for k, item := range items {
+			span := configItem{line: item.Line - 1, end: sectionEnd}
+			if k+1 < len(items) {
+				span.end = items[k+1].Line - 1
+			}
+			fields := d.mappingFields(item)
+
+			switch top[i].Value {
+			case "idl_repos":
+				d.repos = append(d.repos, &configRepo{
+					configItem: span,
+					name:       fields["repo_name"],
+					gitURL:     fields["git_url"],
+					branch:     fields["branch"],
+					commit:     fields["commit"],
+				})
+			case "idls":
+				d.idls = append(d.idls, &configIDL{
+					configItem:  span,
+					repoName:    fields["repo_name"],
+					idlPath:     fields["idl_path"],
+					serviceName: fields["service_name"],
+				})
+			}
+		}
+	}
+
+	return d
+}
+
+func (d *configDocument) mappingFields(node *yaml.Node) map[string]configField {
+	fields := map[string]configField{}
+	if node.Kind != yaml.MappingNode {
+		return fields
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			continue
+		}
+		// yaml columns count characters, from the opening quote of quoted scalars.
+		f := configField{value: value.Value, line: value.Line - 1}
+		line := d.line(f.line)
+		for n := 1; n < value.Column && f.col < len(line); n++ {
+			_, size := utf8.DecodeRuneInString(line[f.col:])
+			f.col += size
+		}
+		if value.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			f.col++
+		}
+		fields[node.Content[i].Value] = f
+	}
+	return fields
+}
+
+func (d *configDocument) repo(name string) *configRepo {
+	for _, repo := range d.repos {
+		if repo.name.value == name {
+			return repo
+		}
+	}
+	return nil
+}
+
+func (d *configDocument) idlAt(line int) *configIDL {
+	for _, idl := range d.idls {
+		if idl.line <= line && line < idl.end {
+			return idl
+		}
+	}
+	return nil
+}
+
+func (d *configDocument) repoDir(name string) string {
+	return filepath.Join(d.idlRoot, name)
+}
+
+// diagnostics reports the syntax error of the document, the IDLs of unknown
+// repos or missing from their cloned repo, and the services generating the
+// same module as another.
+func (d *configDocument) diagnostics() []defines.Diagnostic {
+	diags := []defines.Diagnostic{}
+
+	if d.err != nil {
+		line := 0
+		if m := yamlErrLine.FindStringSubmatch(d.err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			line--
+		}
+		return append(diags, d.diagnostic(configField{line: line}, len(d.line(line)), d.err.Error()))
+	}
+
+	services := map[string]*configIDL{}
+	for _, idl := range d.idls {
+		if name := idl.repoName.value; name != "" {
+			if d.repo(name) == nil {
+				diags = append(diags, d.diagnostic(idl.repoName, len(name), fmt.Sprintf("unknown repo %q, not in idl_repos", name)))
+			} else if path := idl.idlPath.value; path != "" {
+				// Repos not cloned yet are fetched by the next generation.
+				if exist, _ := utils.PathExist(d.repoDir(name)); exist {
+					if exist, _ = utils.PathExist(filepath.Join(d.repoDir(name), path)); !exist {
+						diags = append(diags, d.diagnostic(idl.idlPath, len(path), fmt.Sprintf("IDL file %s not found in repo %s", path, name)))
+					}
+				}
+			}
+		}
+
+		name := idl.serviceName.value
+		if name == "" {
+			continue
+		}
+		formatted := config.FormatServiceName(name)
+		first, ok := services[formatted]
+		if !ok {
+			services[formatted] = idl
+			continue
+		}
+		msg := fmt.Sprintf("duplicate service name %q, also on line %d", name, first.serviceName.line+1)
+		if first.serviceName.value != name {
+			msg = fmt.Sprintf("service %q generates the same module %s as %q on line %d", name, formatted, first.serviceName.value, first.serviceName.line+1)
+		}
+		diags = append(diags, d.diagnostic(idl.serviceName, len(name), msg))
+	}
+
+	return diags
+}
+
+func (d *configDocument) diagnostic(f configField, length int, msg string) defines.Diagnostic {
+	severity := defines.DiagnosticSeverityError
+	source := consts.RGOLsp
+	return defines.Diagnostic{
+		Range:    d.fieldRange(f, length),
+		Severity: &severity,
+		Source:   &source,
+		Message:  msg,
+	}
+}
+
+// hover describes the repo whose name or commit is at line, with the commit
+// and date of its clone.
+func (d *configDocument) hover(line int) *defines.Hover {
+	var (
+		repo  *configRepo
+		field configField
+	)
+	for _, r := range d.repos {
+		for _, f := range []configField{r.name, r.commit} {
+			if f.line == line && f.value != "" {
+				repo, field = r, f
+			}
+		}
+	}
+	for _, idl := range d.idls {
+		if idl.repoName.line == line {
+			repo, field = d.repo(idl.repoName.value), idl.repoName
+		}
+	}
+	if repo == nil {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** %s\n\n", repo.name.value, repo.gitURL.value)
+	if repo.commit.value != "" {
+		fmt.Fprintf(&b, "configured commit: `%s`\n\n", repo.commit.value)
+	} else {
+		fmt.Fprintf(&b, "configured: latest commit of branch `%s`\n\n", repo.branch.value)
+	}
+
+	dir := d.repoDir(repo.name.value)
+	commit, err := utils.GetLatestCommitID(dir)
+	if err != nil {
+		b.WriteString("not cloned yet")
+	} else {
+		date, _ := utils.GetLatestCommitDate(dir)
+		fmt.Fprintf(&b, "resolved commit: `%s` (%s)", commit, date)
+	}
+
+	r := d.fieldRange(field, len(field.value))
+	return &defines.Hover{
+		Contents: defines.MarkupContent{Kind: defines.MarkupKindMarkdown, Value: b.String()},
+		Range:    &r,
+	}
+}
+
+// completion offers the repos of idl_repos for the repo_name of an IDL and
+// the thrift files of its cloned repo for its idl_path.
+func (d *configDocument) completion(pos defines.Position) []defines.CompletionItem {
+	line := d.line(int(pos.Line))
+	col, ok := byteColumn(line, int(pos.Character))
+	if !ok {
+		return nil
+	}
+	m := completionKey.FindStringSubmatch(line[:col])
+	if m == nil {
+		return nil
+	}
+	idl := d.idlAt(int(pos.Line))
+	if idl == nil {
+		return nil
+	}
+
+	typed := configField{value: m[2], line: int(pos.Line), col: col - len(m[2])}
+	item := func(label, detail string, kind defines.CompletionItemKind) defines.CompletionItem {
+		return defines.CompletionItem{
+			Label:    label,
+			Kind:     &kind,
+			Detail:   &detail,
+			TextEdit: defines.TextEdit{Range: d.fieldRange(typed, len(typed.value)), NewText: label},
+		}
+	}
+
+	var items []defines.CompletionItem
+	switch m[1] {
+	case "repo_name":
+		for _, repo := range d.repos {
+			if repo.name.value != "" {
+				items = append(items, item(repo.name.value, repo.gitURL.value, defines.CompletionItemKindModule))
+			}
+		}
+	case "idl_path":
+		if idl.repoName.value == "" {
+			return nil
+		}
+		dir := d.repoDir(idl.repoName.value)
+		_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.IsDir() && entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if !entry.IsDir() && filepath.Ext(path) == consts.ThriftPostfix {
+				rel, _ := filepath.Rel(dir, path)
+				items = append(items, item(filepath.ToSlash(rel), idl.repoName.value, defines.CompletionItemKindFile))
+			}
+			return nil
+		})
+	}
+
+	return items
+}
+
+func (d *configDocument) line(line int) string {
+	if line < 0 || line >= len(d.lines) {
+		return ""
+	}
+	return strings.TrimSuffix(d.lines[line], "\r")
+}
+
+// fieldRange returns the range of the length bytes of f, in the UTF-16
+// columns of LSP.
+func (d *configDocument) fieldRange(f configField, length int) defines.Range {
+	line := d.line(f.line)
+	return defines.Range{
+		Start: defines.Position{Line: uint(f.line), Character: uint(utf16Column(line, f.col))},
+		End:   defines.Position{Line: uint(f.line), Character: uint(utf16Column(line, f.col+length))},
+	}
+}
+
+// utf16Column returns the UTF-16 column of the byte column col of line.
+func utf16Column(line string, col int) int {
+	if col > len(line) {
+		col = len(line)
+	}
+	n := 0
+	for _, r := range line[:col] {
+		n += utf16Len(r)
+	}
+	return n
+}
+
+// byteColumn returns the byte column of the UTF-16 column character of line,
+// or false if it is past the end of line or inside a character.
+func byteColumn(line string, character int) (int, bool) {
+	n := 0
+	for col, r := range line {
+		if n >= character {
+			return col, n == character
+		}
+		n += utf16Len(r)
+	}
+	return len(line), n == character
+}
+
+// configIDLRoot returns the directory the IDL repos of the project of the
+// rgo_config.yaml at path are cloned to.
+func configIDLRoot(path string) string {
+	return filepath.Join(utils.GetDefaultUserPath(), consts.RGOBasePath, utils.ProjectHashPathWithUnderline(filepath.Dir(path)), consts.IDLPath)
+}
+
+// utf16Len returns the number of UTF-16 code units encoding r.
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/TobiasYin/go-lsp/lsp/defines"
+)
+
+const testConfig = `idl_repos:
+  - repo_name: example
+    git_url: https://github.com/cloudwego/kitex-examples.git
+    branch: main
+  - repo_name: remote
+    git_url: https://example.com/remote.git
+    branch: main
+idls:
+  - idl_path: hello/hello.thrift
+    repo_name: example
+    service_name: a.b.c
+  - idl_path: missing.thrift
+    repo_name: example
+    service_name: a-b-c
+  - idl_path: x.thrift
+    repo_name: unknown
+    service_name: a.b.c
+  - idl_path: 
+    repo_name: 
+    service_name: d
+`
+
+func testIDLRoot(t *testing.T) string {
+	idlRoot := t.TempDir()
+	repo := filepath.Join(idlRoot, "example")
+	for _, name := range []string{"hello/hello.thrift", "base/base.thrift", "README.md", ".git/x.thrift"} {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return idlRoot
+}
+
+func TestConfigDocumentDiagnostics(t *testing.T) {
+	d := parseConfigDocument(testConfig, testIDLRoot(t))
+
+	var got []string
+	for _, diag := range d.diagnostics() {
+		got = append(got, diag.Message)
+	}
+	want := []string{
+		"IDL file missing.thrift not found in repo example",
+		`service "a-b-c" generates the same module a_b_c as "a.b.c" on line 11`,
+		`unknown repo "unknown", not in idl_repos`,
+		`duplicate service name "a.b.c", also on line 11`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got diagnostics\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	d = parseConfigDocument("idls:\n  - repo_name: [\n", "")
+	if diags := d.diagnostics(); len(diags) != 1 || diags[0].Range.Start.Line != 1 {
+		t.Errorf("got diagnostics %+v for a syntax error on line 2", diags)
+	}
+}
+
+func TestConfigDocumentCompletion(t *testing.T) {
+	d := parseConfigDocument(testConfig, testIDLRoot(t))
+
+	labels := func(line, character uint) string {
+		var labels []string
+		for _, item := range d.completion(defines.Position{Line: line, Character: character}) {
+			labels = append(labels, item.Label)
+		}
+		sort.Strings(labels)
+		return strings.Join(labels, " ")
+	}
+
+	// The repo_name and idl_path of the last IDL, and of the first.
+	if got := labels(18, uint(len("    repo_name: "))); got != "example remote" {
+		t.Errorf("got repo names %q", got)
+	}
+	if got := labels(17, uint(len("  - idl_path: "))); got != "" {
+		t.Errorf("got IDL paths %q of an IDL without repo", got)
+	}
+	if got := labels(8, uint(len("  - idl_path: hel"))); got != "base/base.thrift hello/hello.thrift" {
+		t.Errorf("got IDL paths %q", got)
+	}
+	// Repos are only completed in idls.
+	if got := labels(1, uint(len("  - repo_name: "))); got != "" {
+		t.Errorf("got repo names %q in idl_repos", got)
+	}
+
+	items := d.completion(defines.Position{Line: 8, Character: uint(len("  - idl_path: hel"))})
+	if len(items) == 0 {
+		t.Fatal("no completion")
+	}
+	if edit := items[0].TextEdit.(defines.TextEdit); edit.Range.Start.Character != uint(len("  - idl_path: ")) {
+		t.Errorf("got edit %+v replacing more than the typed path", edit)
+	}
+}
+
+func TestConfigDocumentRanges(t *testing.T) {
+	const config = `idl_repos:
+  - repo_name: example
+idls:
+  - {service_name: "é😀", repo_name: "nope"}
+  - repo_name: 'example'
+    idl_path: 😀
+`
+	d := parseConfigDocument(config, testIDLRoot(t))
+
+	// Columns are in UTF-16 and skip the quotes of quoted scalars.
+	want := []defines.Range{
+		{Start: defines.Position{Line: 3, Character: 38}, End: defines.Position{Line: 3, Character: 42}},
+		{Start: defines.Position{Line: 5, Character: 14}, End: defines.Position{Line: 5, Character: 16}},
+	}
+	diags := d.diagnostics()
+	if len(diags) != len(want) {
+		t.Fatalf("got diagnostics %+v", diags)
+	}
+	for i, diag := range diags {
+		if diag.Range != want[i] {
+			t.Errorf("got range %+v for %q, want %+v", diag.Range, diag.Message, want[i])
+		}
+	}
+
+	items := d.completion(defines.Position{Line: 5, Character: 16})
+	if len(items) == 0 {
+		t.Fatal("no completion")
+	}
+	if edit := items[0].TextEdit.(defines.TextEdit); edit.Range != want[1] {
+		t.Errorf("got edit range %+v, want %+v", edit.Range, want[1])
+	}
+	if items = d.completion(defines.Position{Line: 5, Character: 15}); len(items) != 0 {
+		t.Errorf("got completion %+v inside a character", items)
+	}
+}
+
+func TestConfigDocumentHover(t *testing.T) {
+	idlRoot := testIDLRoot(t)
+	repo := filepath.Join(idlRoot, "example")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=rgo", "-c", "user.email=rgo@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git %s: %v\n%s", args[0], err, out)
+		}
+	}
+
+	d := parseConfigDocument(testConfig, idlRoot)
+
+	hover := d.hover(9)
+	if hover == nil {
+		t.Fatal("no hover on the repo_name of an IDL")
+	}
+	if got := hover.Contents.(defines.MarkupContent).Value; !strings.Contains(got, "**example**") || !strings.Contains(got, "resolved commit: `") {
+		t.Errorf("got hover %q", got)
+	}
+
+	if hover = d.hover(4); hover == nil || !strings.Contains(hover.Contents.(defines.MarkupContent).Value, "not cloned yet") {
+		t.Errorf("got hover %+v of a repo not cloned", hover)
+	}
+
+	if hover = d.hover(10); hover != nil {
+		t.Errorf("got hover %+v on a service name", hover)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"sync"
+
+	"github.com/TobiasYin/go-lsp/lsp"
+	"github.com/TobiasYin/go-lsp/lsp/defines"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/rlog"
+)
+
+// configDocuments holds the rgo_config.yaml documents opened in the editor.
+type configDocuments struct {
+	server *lsp.Server
+
+	mu   sync.Mutex
+	text map[defines.DocumentUri]string
+	docs map[defines.DocumentUri]*configDocument
+}
+
+// setConfigMethods handles the rgo_config.yaml documents, other documents
+// keep the methods of setAllMethodsNull.
+func setConfigMethods(s *lsp.Server) {
+	c := &configDocuments{
+		server: s,
+		text:   map[defines.DocumentUri]string{},
+		docs:   map[defines.DocumentUri]*configDocument{},
+	}
+
+	s.OnDidOpenTextDocument(func(ctx context.Context, req *defines.DidOpenTextDocumentParams) (err error) {
+		c.update(req.TextDocument.Uri, req.TextDocument.Text)
+		return nil
+	})
+
+	s.OnDidChangeTextDocument(func(ctx context.Context, req *defines.DidChangeTextDocumentParams) (err error) {
+		// The server syncs full documents, the last change holds the text.
+		for k := len(req.ContentChanges) - 1; k >= 0; k-- {
+			if text, ok := req.ContentChanges[k].Text.(string); ok {
+				c.update(req.TextDocument.Uri, text)
+				break
+			}
+		}
+		return nil
+	})
+
+	s.OnDidSaveTextDocument(func(ctx context.Context, req *defines.DidSaveTextDocumentParams) (err error) {
+		// IDL repos may have been cloned since the document changed.
+		c.mu.Lock()
+		text, ok := c.text[req.TextDocument.Uri]
+		c.mu.Unlock()
+		if req.Text != nil {
+			text, ok = *req.Text, true
+		}
+		if ok {
+			c.update(req.TextDocument.Uri, text)
+		}
+		return nil
+	})
+
+	s.OnDidCloseTextDocument(func(ctx context.Context, req *defines.DidCloseTextDocumentParams) (err error) {
+		c.mu.Lock()
+		_, ok := c.docs[req.TextDocument.Uri]
+		delete(c.text, req.TextDocument.Uri)
+		delete(c.docs, req.TextDocument.Uri)
+		c.mu.Unlock()
+		if ok {
+			c.publish(req.TextDocument.Uri, []defines.Diagnostic{})
+		}
+		return nil
+	})
+
+	s.OnHover(func(ctx context.Context, req *defines.HoverParams) (result *defines.Hover, err error) {
+		if d := c.get(req.TextDocument.Uri); d != nil {
+			return d.hover(int(req.Position.Line)), nil
+		}
+		return nil, nil
+	})
+
+	s.OnCompletion(func(ctx context.Context, req *defines.CompletionParams) (result *[]defines.CompletionItem, err error) {
+		if d := c.get(req.TextDocument.Uri); d != nil {
+			if items := d.completion(req.Position); len(items) > 0 {
+				return &items, nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+func (c *configDocuments) get(uri defines.DocumentUri) *configDocument {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.docs[uri]
+}
+
+// update parses the text of uri if it's an rgo_config.yaml and publishes its
+// diagnostics.
+func (c *configDocuments) update(uri defines.DocumentUri, text string) {
+	path := uriPath(uri)
+	if filepath.Base(path) != consts.RGOConfigFile {
+		return
+	}
+
+	d := parseConfigDocument(text, configIDLRoot(path))
+
+	c.mu.Lock()
+	c.text[uri] = text
+	c.docs[uri] = d
+	c.mu.Unlock()
+
+	c.publish(uri, d.diagnostics())
+}
+
+func (c *configDocuments) publish(uri defines.DocumentUri, diags []defines.Diagnostic) {
+	// defines.PublishDiagnosticsParams omits empty diagnostics, which clear
+	// the previous ones.
+	params, err := json.Marshal(struct {
+		Uri         defines.DocumentUri  `json:"uri"`
+		Diagnostics []defines.Diagnostic `json:"diagnostics"`
+	}{uri, diags})
+	if err != nil {
+		rlog.Errorf("failed to marshal diagnostics: %v", err)
+		return
+	}
+
+	if err = c.server.SendNotification(consts.MethodPublishDiagnostics, params); err != nil {
+		rlog.Errorf("failed to publish diagnostics of %s: %v", uri, err)
+	}
+}
+
+func uriPath(uri defines.DocumentUri) string {
+	u, err := url.Parse(string(uri))
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
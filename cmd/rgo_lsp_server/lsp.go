@@ -56,6 +56,7 @@ func RunLspServer(cancel context.CancelFunc, server *lsp.Server) {
 	}()
 
 	setAllMethodsNull(server)
+	setConfigMethods(server)
 
 	server.Run()
 }
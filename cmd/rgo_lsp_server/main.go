@@ -38,7 +38,7 @@ func main() {
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 
 	server := lsp.NewServer(&lsp.Options{CompletionProvider: &defines.CompletionOptions{
-		TriggerCharacters: &[]string{"."},
+		TriggerCharacters: &[]string{".", " ", "/"},
 	}})
 
 	go func() {
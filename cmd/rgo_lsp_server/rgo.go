@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"os"
 	"path/filepath"
 	"runtime/debug"
 
@@ -35,8 +36,19 @@ import (
 
 var isRunning = make(chan struct{}, 1)
 
+var configPath = consts.RGOConfigPath
+
 func initConfig(server *lsp.Server) *generator.RGOGenerator {
-	var err error
+	root, err := utils.GetProjectRoot()
+	if err != nil {
+		panic(err)
+	}
+
+	// The generator works in the project root, like the rgo command.
+	if err = os.Chdir(root); err != nil {
+		panic(err)
+	}
+	configPath = filepath.Join(root, consts.RGOConfigFile)
 
 	currentPath, err := utils.GetProjectHashPathWithUnderline()
 	if err != nil {
@@ -47,7 +59,7 @@ func initConfig(server *lsp.Server) *generator.RGOGenerator {
 
 	rlog.InitLogger(filepath.Join(rgoBasePath, consts.LogPath, consts.RGOLsp), server)
 
-	c, err := config.ReadConfig(consts.RGOConfigPath)
+	c, err := config.ReadConfig(configPath)
 	if err != nil {
 		rlog.Warn("read rgo_config failed, file not found", zap.Error(err))
 	}
@@ -91,7 +103,7 @@ func WatchConfig(g *generator.RGOGenerator, ctx context.Context) {
 		}
 
 		viper.Reset()
-		c, err := config.ReadConfig(consts.RGOConfigPath)
+		c, err := config.ReadConfig(configPath)
 		if err != nil {
 			rlog.Error("read rgo_config failed, file not found", zap.Error(err))
 			return
@@ -0,0 +1,242 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/rlog"
+)
+
+type (
+	// daemonRequest is sent by the driver process to the daemon, one per
+	// connection.
+	daemonRequest struct {
+		Dir      string         `json:"dir"`
+		Patterns []string       `json:"patterns"`
+		Request  *DriverRequest `json:"request"`
+	}
+
+	daemonResponse struct {
+		Response json.RawMessage `json:"response,omitempty"`
+		Error    string          `json:"error,omitempty"`
+	}
+)
+
+// socketPath returns the path of the socket of the daemon of the project.
+// Unix socket paths are limited to about 100 bytes, too few for rgoBasePath.
+func socketPath() (string, error) {
+	dir, err := socketDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(rgoBasePath))
+	return filepath.Join(dir, "driver-"+hex.EncodeToString(sum[:8])+".sock"), nil
+}
+
+// socketDir returns the directory of the daemon sockets of the current user,
+// which only they can access, so that nobody else can serve them metadata.
+func socketDir() (string, error) {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user cache directory: %v", err)
+		}
+		dir = cacheDir
+	}
+	dir = filepath.Join(dir, consts.DriverSocketDir)
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create directory: %v", err)
+	}
+	if err := checkOwner(dir); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// serve answers the requests sent to the socket at path until ctx is done or
+// no request came for consts.DriverDaemonIdleTimeout.
+func serve(ctx context.Context, path string) error {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("a driver daemon is already serving %s", path)
+	}
+	// The socket of a daemon which didn't stop cleanly.
+	_ = os.Remove(path)
+
+	ln, err := listenUnix(path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", path, err)
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		active int32
+		idle   *time.Timer
+	)
+	idle = time.AfterFunc(consts.DriverDaemonIdleTimeout, func() {
+		if atomic.LoadInt32(&active) > 0 {
+			idle.Reset(consts.DriverDaemonIdleTimeout)
+			return
+		}
+		rlog.Infof("driver daemon idle for %v, stopping", consts.DriverDaemonIdleTimeout)
+		cancel()
+	})
+	defer idle.Stop()
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	d := newDriver(filepath.Join(rgoBasePath, consts.PkgMetaPath), true)
+	rlog.Infof("driver daemon serving %s", path)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %v", err)
+		}
+
+		idle.Reset(consts.DriverDaemonIdleTimeout)
+		atomic.AddInt32(&active, 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer atomic.AddInt32(&active, -1)
+			d.serveConn(ctx, conn)
+		}()
+	}
+}
+
+func (d *driver) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	req := &daemonRequest{}
+	if err := json.NewDecoder(conn).Decode(req); err != nil {
+		// Connections closed without a request only checked the daemon is up.
+		if err != io.EOF {
+			rlog.Errorf("unable to decode daemon request: %v", err)
+		}
+		return
+	}
+	if req.Request == nil {
+		req.Request = &DriverRequest{}
+	}
+
+	resp := &daemonResponse{}
+	ret, err := d.load(ctx, req.Dir, req.Request, req.Patterns, nil)
+	if err == nil {
+		resp.Response, err = json.Marshal(ret)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	if err = json.NewEncoder(conn).Encode(resp); err != nil {
+		rlog.Errorf("failed to write daemon response: %v", err)
+	}
+}
+
+// runInDaemon has the daemon listening at path answer req and writes its
+// response to out. served is false when no daemon listens at path, in which
+// case the request is left to the caller, and the daemon is started if
+// consts.DriverDaemonEnv asks for it.
+func runInDaemon(ctx context.Context, path, dir string, req *DriverRequest, patterns []string, out io.Writer) (served bool, err error) {
+	// Only a daemon of the current user is trusted to answer.
+	if err = checkOwner(path); err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("refusing the driver daemon socket: %v", err)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		if os.Getenv(consts.DriverDaemonEnv) == "on" {
+			return false, startDaemon()
+		}
+		return false, nil
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	err = json.NewEncoder(conn).Encode(&daemonRequest{Dir: dir, Patterns: patterns, Request: req})
+	if err != nil {
+		return false, fmt.Errorf("failed to send request to the driver daemon: %v", err)
+	}
+
+	resp := &daemonResponse{}
+	if err = json.NewDecoder(conn).Decode(resp); err != nil {
+		return false, fmt.Errorf("failed to read the driver daemon response: %v", err)
+	}
+	if resp.Error != "" {
+		return true, errors.New(resp.Error)
+	}
+
+	_, err = out.Write(resp.Response)
+	return true, err
+}
+
+// startDaemon starts the daemon of the project in the background, for the
+// next requests.
+func startDaemon() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get driver executable: %v", err)
+	}
+
+	cmd := exec.Command(exe, consts.DriverServeCommand)
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start the driver daemon: %v", err)
+	}
+
+	return cmd.Process.Release()
+}
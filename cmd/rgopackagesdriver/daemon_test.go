@@ -0,0 +1,145 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/pkgmeta"
+	"github.com/cloudwego-contrib/rgo/pkg/rlog"
+	"golang.org/x/tools/go/packages"
+)
+
+func writeMeta(t *testing.T, service string, pkgs ...*packages.Package) {
+	path := filepath.Join(rgoBasePath, consts.PkgMetaPath, service, consts.PkgMetaFile)
+	if err := pkgmeta.Write(path, pkgmeta.Header{}, pkgs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDaemon checks that the daemon answers the requests of the driver
+// process and reads pkg_meta again once it changed.
+func TestDaemon(t *testing.T) {
+	dir := t.TempDir()
+	defer func(path string) { rgoBasePath = path }(rgoBasePath)
+	rgoBasePath = filepath.Join(dir, "cache")
+	rlog.InitLogger(filepath.Join(rgoBasePath, consts.LogPath, consts.RGOPackagesDriver), nil)
+
+	writeMeta(t, "echo", &packages.Package{ID: "rgo/echo", Name: "echo", PkgPath: "rgo/echo"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	path := filepath.Join(dir, "d.sock")
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, path) }()
+	defer func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Error(err)
+		}
+	}()
+
+	for i := 0; ; i++ {
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if i == 100 {
+			t.Fatalf("daemon not listening: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()&0o077 != 0 {
+			t.Errorf("socket mode %v, want no access for other users", info.Mode().Perm())
+		}
+	}
+
+	roots := func(pattern string) []string {
+		var out bytes.Buffer
+		ok, err := runInDaemon(context.Background(), path, dir, &DriverRequest{Mode: LoadMode(packages.NeedName)}, []string{pattern}, &out)
+		if !ok || err != nil {
+			t.Fatalf("runInDaemon(%s) = %v, %v", pattern, ok, err)
+		}
+		resp := &packages.DriverResponse{}
+		if err = json.Unmarshal(out.Bytes(), resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Roots
+	}
+
+	if got := roots("rgo/echo"); len(got) != 1 || got[0] != "rgo/echo" {
+		t.Errorf("got roots %q, want [rgo/echo]", got)
+	}
+
+	writeMeta(t, "calc", &packages.Package{ID: "rgo/calc", Name: "calc", PkgPath: "rgo/calc"})
+	if got := roots("rgo/calc"); len(got) != 1 || got[0] != "rgo/calc" {
+		t.Errorf("got roots %q after adding rgo/calc, want [rgo/calc]", got)
+	}
+
+	if ok, err := runInDaemon(context.Background(), filepath.Join(dir, "none.sock"), dir, &DriverRequest{}, nil, &bytes.Buffer{}); ok || err != nil {
+		t.Errorf("runInDaemon without daemon = %v, %v, want false, nil", ok, err)
+	}
+}
+
+// TestSocketDir checks that the sockets live in a directory only the user can
+// access, and that a looser one is refused.
+func TestSocketDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are left to the profile directory on windows")
+	}
+
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	path, err := socketPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != filepath.Join(runtimeDir, consts.DriverSocketDir) {
+		t.Errorf("got socket %s, want it in %s", path, runtimeDir)
+	}
+
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o700 {
+		t.Errorf("socket directory mode %v, want 0700", info.Mode().Perm())
+	}
+
+	if err = os.Chmod(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = socketPath(); err == nil {
+		t.Error("socketPath accepted a directory other users can access")
+	}
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego-contrib/rgo/cmd/rgopackagesdriver/internal"
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/pkgmeta"
+	"github.com/cloudwego-contrib/rgo/pkg/rlog"
+	"github.com/cloudwego-contrib/rgo/pkg/utils"
+	"golang.org/x/tools/go/packages"
+)
+
+// driver answers the requests of go/packages with the packages of the default
+// driver and the rgo packages read from pkg_meta. The daemon keeps one across
+// requests, caching what it read until pkg_meta changes.
+type driver struct {
+	metaPath string
+	cache    bool
+
+	mu sync.Mutex
+	// metaStamp identifies the pkg_meta files meta was read from.
+	metaStamp string
+	meta      *rgoMeta
+	// implicit caches the packages imported by rgo packages by the request
+	// loading them.
+	implicit map[string][]*packages.Package
+}
+
+func newDriver(metaPath string, cache bool) *driver {
+	return &driver{
+		metaPath: metaPath,
+		cache:    cache,
+		implicit: map[string][]*packages.Package{},
+	}
+}
+
+// load answers req for patterns, resolving relative ones against dir. tr, if
+// not nil, records how the response was built.
+func (d *driver) load(ctx context.Context, dir string, req *DriverRequest, patterns []string, tr *loadTrace) (*packages.DriverResponse, error) {
+	env := append([]string(nil), req.Env...)
+	for k := len(env) - 1; k >= 0; k-- {
+		if strings.Contains(env[k], "GOPACKAGESDRIVER") {
+			env = append(env[:k], env[k+1:]...)
+			break
+		}
+	}
+
+	cfg := &packages.Config{
+		Mode:       packages.LoadMode(req.Mode),
+		Context:    ctx,
+		Dir:        dir,
+		Env:        env,
+		Overlay:    req.Overlay,
+		Tests:      req.Tests,
+		BuildFlags: req.BuildFlags,
+	}
+
+	start := time.Now()
+	meta, err := d.targetPackages()
+	if err != nil {
+		rlog.Warnf("unable get target packages from path %s: %v", d.metaPath, err)
+		meta = &rgoMeta{}
+	}
+	for _, err := range meta.skipped {
+		rlog.Warnf("skipped rgo metadata: %v", err)
+		tr.warn(err.Error())
+	}
+	tr.step("rgo metadata", start, fmt.Sprintf("%d packages in %s", len(meta.entries), d.metaPath))
+
+	// gopls runs the driver in the directory of the packages it loads, which
+	// may be below the project root.
+	root, err := utils.FindProjectRoot(dir)
+	if err != nil {
+		rlog.Warnf("failed to find project root of %s: %v", dir, err)
+		root = dir
+	}
+
+	projectModule, err := config.ReadProjectModule(filepath.Join(root, consts.RGOConfigFile))
+	if err != nil {
+		rlog.Infof("using the default project module: %v", err)
+		projectModule = consts.RGODefaultModuleName
+	}
+
+	modules := newRGOModules(projectModule, meta.entries)
+	query := newPackageQuery(dir, meta.entries, modules, req.Tests)
+
+	var (
+		roots      []string
+		goPatterns []string
+		isRoot     = map[string]bool{}
+	)
+	for _, pattern := range patterns {
+		ids, handled := query.match(pattern)
+		for _, id := range ids {
+			if !isRoot[id] {
+				isRoot[id] = true
+				roots = append(roots, id)
+			}
+		}
+		if !handled {
+			goPatterns = append(goPatterns, pattern)
+		}
+		tr.pattern(pattern, ids, handled)
+	}
+
+	// Patterns only matching rgo packages aren't passed to the go command,
+	// which can't load them.
+	ret := &packages.DriverResponse{Compiler: "gc", Arch: goArch(env)}
+	if len(goPatterns) > 0 || len(patterns) == 0 {
+		start = time.Now()
+		ret, err = internal.DefaultDriverResponse(cfg, goPatterns...)
+		if err != nil {
+			rlog.Errorf("failed to get default driver response: %v", err)
+			return nil, err
+		}
+		tr.step("default driver", start, fmt.Sprintf("%d packages for %q", len(ret.Packages), goPatterns))
+		tr.origin(ret.Packages, "default driver")
+	}
+
+	for k := len(ret.Packages) - 1; k >= 0; k-- {
+		if len(ret.Packages[k].Errors) > 0 && modules.contains(ret.Packages[k].PkgPath) {
+			ret.Packages = append(ret.Packages[:k], ret.Packages[k+1:]...)
+		}
+	}
+
+	rgoEntries := query.closure(roots, ret.Packages)
+	rgoPkgs := make([]*packages.Package, 0, len(rgoEntries))
+	for _, entry := range rgoEntries {
+		pkg, err := entry.Package()
+		if err != nil {
+			rlog.Errorf("failed to read rgo metadata of %s: %v", meta.services[entry.ID], err)
+			return nil, err
+		}
+		tr.origin([]*packages.Package{pkg}, "rgo metadata of "+meta.services[pkg.ID])
+		rgoPkgs = append(rgoPkgs, pkg)
+	}
+
+	ret.Roots = append(ret.Roots, roots...)
+	ret.Packages = append(rgoPkgs, ret.Packages...)
+
+	// The packages imported by rgo packages, and their dependencies, aren't
+	// in their metadata.
+	if imports := modules.imports(rgoEntries); len(imports) > 0 {
+		start = time.Now()
+		implicit, err := d.implicitPackages(cfg, imports)
+		if err != nil {
+			rlog.Errorf("failed to load the imports of rgo packages: %v", err)
+			return nil, err
+		}
+		tr.step("implicit packages", start, fmt.Sprintf("%d packages for %q", len(implicit), imports))
+
+		inResponse := make(map[string]bool, len(ret.Packages))
+		for _, pkg := range ret.Packages {
+			inResponse[pkg.ID] = true
+		}
+		for _, pkg := range implicit {
+			if !inResponse[pkg.ID] {
+				tr.origin([]*packages.Package{pkg}, "imports of rgo packages")
+				ret.Packages = append(ret.Packages, pkg)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// rgoMeta is what the driver read from pkg_meta.
+type rgoMeta struct {
+	entries []*pkgmeta.Entry
+	// services maps the IDs of entries to the services of their metadata.
+	services map[string]string
+	// skipped holds the errors of the metadata files that couldn't be read.
+	skipped []error
+}
+
+// targetPackages returns the rgo packages of pkg_meta, read again only when
+// its files changed if d caches them.
+func (d *driver) targetPackages() (*rgoMeta, error) {
+	if !d.cache {
+		return getTargetPackages(d.metaPath)
+	}
+
+	stamp, err := metaStamp(d.metaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if stamp == d.metaStamp && d.meta != nil {
+		return d.meta, nil
+	}
+
+	meta, err := getTargetPackages(d.metaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	rlog.Infof("loaded %d rgo packages from %s", len(meta.entries), d.metaPath)
+	d.metaStamp, d.meta = stamp, meta
+	// Regenerated modules may depend on other versions of the implicit packages.
+	d.implicit = map[string][]*packages.Package{}
+
+	return meta, nil
+}
+
+// implicitPackages loads imports, and their dependencies, with cfg, once per
+// load mode, environment and build flags if d caches them.
+func (d *driver) implicitPackages(cfg *packages.Config, imports []string) ([]*packages.Package, error) {
+	load := func() ([]*packages.Package, error) {
+		// Tests of the imported packages aren't asked for.
+		loadCfg := *cfg
+		loadCfg.Tests = false
+		resp, err := internal.DefaultDriverResponse(&loadCfg, imports...)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Packages, nil
+	}
+	if !d.cache {
+		return load()
+	}
+
+	key := fmt.Sprint(cfg.Mode, cfg.Dir, cfg.Env, cfg.BuildFlags, imports)
+
+	d.mu.Lock()
+	pkgs, ok := d.implicit[key]
+	d.mu.Unlock()
+	if ok {
+		return pkgs, nil
+	}
+
+	pkgs, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	d.implicit[key] = pkgs
+	d.mu.Unlock()
+
+	return pkgs, nil
+}
+
+// metaStamp returns a string changing whenever a metadata file of path is
+// added, removed or rewritten.
+func metaStamp(path string) (string, error) {
+	directories, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	var stamp strings.Builder
+	for _, dir := range directories {
+		if !dir.IsDir() {
+			continue
+		}
+		for _, name := range []string{consts.PkgMetaFile, consts.LegacyPkgMetaFile} {
+			info, err := os.Stat(filepath.Join(path, dir.Name(), name))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&stamp, "%s/%s:%d:%d;", dir.Name(), name, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+
+	return stamp.String(), nil
+}
+
+// goArch returns the GOARCH of env, defaulting to the one of the driver.
+func goArch(env []string) string {
+	for k := len(env) - 1; k >= 0; k-- {
+		if strings.HasPrefix(env[k], "GOARCH=") {
+			return strings.TrimPrefix(env[k], "GOARCH=")
+		}
+	}
+	return runtime.GOARCH
+}
+
+// getTargetPackages reads the package metadata of every service in path,
+// skipping the files it can't read.
+func getTargetPackages(path string) (*rgoMeta, error) {
+	meta := &rgoMeta{services: map[string]string{}}
+
+	// Check if the path directory exists
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return meta, nil
+	}
+
+	// Read all subdirectories and files under the path directory
+	directories, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	// Traverse the first level subdirectory
+	for _, dir := range directories {
+		if !dir.IsDir() {
+			continue
+		}
+
+		metaPath := filepath.Join(path, dir.Name(), consts.PkgMetaFile)
+		file, err := pkgmeta.Read(metaPath)
+		if os.IsNotExist(err) {
+			legacyPath := filepath.Join(path, dir.Name(), consts.LegacyPkgMetaFile)
+			if _, statErr := os.Stat(legacyPath); statErr == nil {
+				meta.skipped = append(meta.skipped, &pkgmeta.IncompatibleError{Path: legacyPath})
+			}
+			continue
+		}
+		if err != nil {
+			meta.skipped = append(meta.skipped, err)
+			continue
+		}
+
+		for _, entry := range file.Entries {
+			meta.services[entry.ID] = dir.Name()
+		}
+		meta.entries = append(meta.entries, file.Entries...)
+	}
+
+	return meta, nil
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"golang.org/x/tools/go/packages"
+)
+
+// goplsMode is the load mode gopls requests, used by explain.
+const goplsMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+	packages.NeedDeps | packages.NeedTypesSizes | packages.NeedModule | packages.NeedEmbedFiles
+
+type (
+	// loadTrace records how the driver built a response. Its methods do
+	// nothing on a nil trace.
+	loadTrace struct {
+		steps    []traceStep
+		patterns []tracePattern
+		// origins maps the IDs of the packages of the response to where
+		// they come from.
+		origins map[string]string
+		// warnings are the problems not failing the load.
+		warnings []string
+	}
+
+	traceStep struct {
+		name     string
+		duration time.Duration
+		detail   string
+	}
+
+	tracePattern struct {
+		pattern string
+		ids     []string
+		handled bool
+	}
+)
+
+func (t *loadTrace) step(name string, start time.Time, detail string) {
+	if t != nil {
+		t.steps = append(t.steps, traceStep{name: name, duration: time.Since(start), detail: detail})
+	}
+}
+
+func (t *loadTrace) pattern(pattern string, ids []string, handled bool) {
+	if t != nil {
+		t.patterns = append(t.patterns, tracePattern{pattern: pattern, ids: ids, handled: handled})
+	}
+}
+
+func (t *loadTrace) warn(warning string) {
+	if t != nil {
+		t.warnings = append(t.warnings, warning)
+	}
+}
+
+func (t *loadTrace) origin(pkgs []*packages.Package, origin string) {
+	if t == nil {
+		return
+	}
+	if t.origins == nil {
+		t.origins = map[string]string{}
+	}
+	for _, pkg := range pkgs {
+		t.origins[pkg.ID] = origin
+	}
+}
+
+// explain loads the patterns of args in process, the way gopls asks for
+// them, and prints the response with the origin of each package and the time
+// each step took.
+func explain(ctx context.Context, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet(consts.DriverExplainCommand, flag.ContinueOnError)
+	fs.SetOutput(out)
+	tests := fs.Bool("tests", false, "also load test packages")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: rgopackagesdriver %s [-tests] <pattern>...\n", consts.DriverExplainCommand)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %v", err)
+	}
+
+	req := &DriverRequest{Mode: LoadMode(goplsMode), Env: os.Environ(), Tests: *tests}
+	tr := &loadTrace{}
+
+	start := time.Now()
+	ret, err := newDriver(filepath.Join(rgoBasePath, consts.PkgMetaPath), false).load(ctx, wd, req, fs.Args(), tr)
+	if err != nil {
+		return err
+	}
+	total := time.Since(start)
+
+	fmt.Fprintf(out, "directory: %s\n", wd)
+	fmt.Fprintf(out, "cache: %s\n", rgoBasePath)
+	if path, err := socketPath(); err != nil {
+		fmt.Fprintf(out, "daemon: %v\n", err)
+	} else if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		fmt.Fprintf(out, "daemon: serving %s, explain loads in process\n", path)
+	} else {
+		fmt.Fprintf(out, "daemon: not running\n")
+	}
+
+	if len(tr.warnings) > 0 {
+		fmt.Fprintf(out, "\nwarnings:\n")
+		for _, w := range tr.warnings {
+			fmt.Fprintf(out, "  %s\n", w)
+		}
+	}
+
+	fmt.Fprintf(out, "\npatterns:\n")
+	for _, p := range tr.patterns {
+		to := "default driver"
+		if p.handled {
+			to = "rgo metadata only"
+		}
+		fmt.Fprintf(out, "  %s -> %s, rgo packages %q\n", p.pattern, to, p.ids)
+	}
+
+	fmt.Fprintf(out, "\ntiming:\n")
+	for _, s := range tr.steps {
+		fmt.Fprintf(out, "  %-18s %10v  %s\n", s.name, s.duration.Round(time.Microsecond), s.detail)
+	}
+	fmt.Fprintf(out, "  %-18s %10v\n", "total", total.Round(time.Microsecond))
+
+	fmt.Fprintf(out, "\nroots:\n")
+	for _, id := range ret.Roots {
+		fmt.Fprintf(out, "  %s (%s)\n", id, tr.origins[id])
+	}
+
+	fmt.Fprintf(out, "\npackages (%d):\n", len(ret.Packages))
+	for _, pkg := range ret.Packages {
+		fmt.Fprintf(out, "  %s (%s)\n", pkg.ID, tr.origins[pkg.ID])
+		for _, e := range pkg.Errors {
+			fmt.Fprintf(out, "    error: %s\n", e)
+		}
+	}
+
+	return nil
+}
+
+// dumpedRequest is a DriverRequest as it's dumped to be attached to bug
+// reports: only the Go environment is kept, without proxy credentials, and
+// overlays are listed without their contents.
+type dumpedRequest struct {
+	Mode       LoadMode `json:"mode"`
+	Env        []string `json:"env"`
+	BuildFlags []string `json:"build_flags"`
+	Tests      bool     `json:"tests"`
+	Overlay    []string `json:"overlay,omitempty"`
+}
+
+func newDumpedRequest(req *DriverRequest) *dumpedRequest {
+	dumped := &dumpedRequest{Mode: req.Mode, BuildFlags: req.BuildFlags, Tests: req.Tests}
+
+	for _, kv := range req.Env {
+		if !strings.HasPrefix(kv, "GO") && !strings.HasPrefix(kv, "CGO") {
+			continue
+		}
+		if strings.HasPrefix(kv, "GOPROXY=") {
+			kv = "GOPROXY=" + redactProxies(strings.TrimPrefix(kv, "GOPROXY="))
+		}
+		dumped.Env = append(dumped.Env, kv)
+	}
+
+	for path := range req.Overlay {
+		dumped.Overlay = append(dumped.Overlay, path)
+	}
+	sort.Strings(dumped.Overlay)
+
+	return dumped
+}
+
+// redactProxies removes the credentials of the URLs of a GOPROXY list.
+func redactProxies(proxies string) string {
+	fields := strings.FieldsFunc(proxies, func(r rune) bool { return r == ',' || r == '|' })
+	for _, field := range fields {
+		u, err := url.Parse(field)
+		if err != nil || u.User == nil {
+			continue
+		}
+		u.User = url.User("redacted")
+		proxies = strings.Replace(proxies, field, u.String(), 1)
+	}
+	return proxies
+}
+
+// dumpRequest writes a driver request, its response and its error to the dump
+// directory of the log directory, as asked by consts.DriverDumpEnv.
+func dumpRequest(args []string, req *DriverRequest, resp []byte, reqErr error, duration time.Duration) error {
+	dump := struct {
+		Time     time.Time       `json:"time"`
+		Dir      string          `json:"dir"`
+		Args     []string        `json:"args"`
+		Request  *dumpedRequest  `json:"request"`
+		Response json.RawMessage `json:"response,omitempty"`
+		Error    string          `json:"error,omitempty"`
+		Duration string          `json:"duration"`
+	}{
+		Time:     time.Now(),
+		Args:     args,
+		Request:  newDumpedRequest(req),
+		Duration: duration.String(),
+	}
+	dump.Dir, _ = os.Getwd()
+	if json.Valid(resp) {
+		dump.Response = resp
+	}
+	if reqErr != nil {
+		dump.Error = reqErr.Error()
+	}
+
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal dump: %v", err)
+	}
+
+	dir := filepath.Join(rgoBasePath, consts.LogPath, consts.RGOPackagesDriver, consts.DriverDumpPath)
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create dump directory: %v", err)
+	}
+
+	name := fmt.Sprintf("%s-%d.json", dump.Time.Format("20060102-150405.000000"), os.Getpid())
+	if err = os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write dump: %v", err)
+	}
+
+	return nil
+}
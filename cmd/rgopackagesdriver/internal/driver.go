@@ -0,0 +1,127 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typesModes are the parts of a load computed by go/packages itself rather
+// than by its driver.
+const typesModes = packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+
+// DefaultDriverResponse returns the response the go command driver of
+// go/packages gives to a request of cfg for patterns. The packages are loaded
+// with packages.Load, the external driver disabled, and flattened back into
+// the response of a driver: the loaded packages are its roots, and they and
+// all the packages they import its packages.
+func DefaultDriverResponse(cfg *packages.Config, patterns ...string) (*packages.DriverResponse, error) {
+	loadCfg := *cfg
+	loadCfg.Env = append(envOrEnviron(cfg.Env), "GOPACKAGESDRIVER=off")
+
+	// Types and syntax are left to the caller, which loads them from the
+	// files and export data listed in the response, as go list would list them.
+	loadCfg.Mode = cfg.Mode &^ typesModes
+	if cfg.Mode&typesModes != 0 {
+		loadCfg.Mode |= packages.NeedCompiledGoFiles
+	}
+	if cfg.Mode&packages.NeedExportFile != 0 || cfg.Mode&packages.NeedTypes != 0 && cfg.Mode&packages.NeedDeps == 0 {
+		loadCfg.Mode |= packages.NeedExportFile
+	}
+
+	// Like go list, dependencies are only listed when the import graph is
+	// needed, which NeedDeps and types imply. go/packages resolves the
+	// imports of the roots against them even without NeedDeps.
+	listDeps := cfg.Mode&(packages.NeedImports|packages.NeedDeps|packages.NeedTypes|packages.NeedTypesInfo) != 0
+	if listDeps {
+		loadCfg.Mode |= packages.NeedImports
+	}
+
+	roots, err := packages.Load(&loadCfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &packages.DriverResponse{}
+	for _, pkg := range roots {
+		resp.Roots = append(resp.Roots, pkg.ID)
+	}
+	if listDeps {
+		packages.Visit(roots, nil, func(pkg *packages.Package) {
+			resp.Packages = append(resp.Packages, pkg)
+		})
+	} else {
+		resp.Packages = roots
+	}
+
+	resp.Compiler, resp.Arch, resp.GoVersion, err = goContext(&loadCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// goContext returns the compiler, GOARCH and minor Go version of the go
+// command run with the directory, environment and build flags of cfg.
+func goContext(cfg *packages.Config) (compiler, arch string, goVersion int, err error) {
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	args := append([]string{"list", "-e", "-f", "{{context.Compiler}} {{context.GOARCH}} {{context.ReleaseTags}}"}, cfg.BuildFlags...)
+	cmd := exec.CommandContext(ctx, "go", append(args, "--", "unsafe")...)
+	cmd.Dir = cfg.Dir
+	cmd.Env = cfg.Env
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to run go list: %v: %s", err, stderr.String())
+	}
+
+	// e.g. gc amd64 [go1.1 go1.2 ... go1.22]
+	fields := strings.Fields(strings.NewReplacer("[", "", "]", "").Replace(string(out)))
+	if len(fields) < 3 {
+		return "", "", 0, fmt.Errorf("unexpected go list output %q", out)
+	}
+
+	last := fields[len(fields)-1]
+	goVersion, err = strconv.Atoi(strings.TrimPrefix(last, "go1."))
+	if err != nil {
+		return "", "", 0, fmt.Errorf("unexpected release tag %q", last)
+	}
+
+	return fields[0], fields[1], goVersion, nil
+}
+
+func envOrEnviron(env []string) []string {
+	if env == nil {
+		return os.Environ()
+	}
+	return env[:len(env):len(env)]
+}
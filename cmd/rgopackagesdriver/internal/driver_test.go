@@ -0,0 +1,191 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// goplsMode is the load mode gopls requests from drivers.
+const goplsMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+	packages.NeedDeps | packages.NeedTypesSizes | packages.NeedModule | packages.NeedEmbedFiles
+
+func testModule(t *testing.T) string {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module example.com/m\n\ngo 1.18\n",
+		"a/a.go":      "package a\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/m/b\"\n)\n\nfunc A() string { return fmt.Sprint(b.B) }\n",
+		"a/a_test.go": "package a\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n",
+		"b/b.go":      "package b\n\nconst B = 1\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func goCommand(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("go %s: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func responseIDs(resp *packages.DriverResponse) []string {
+	var ids []string
+	for _, pkg := range resp.Packages {
+		ids = append(ids, pkg.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// TestDefaultDriverResponse checks that the response lists the packages go
+// list does, the way the go command driver of go/packages reports them.
+func TestDefaultDriverResponse(t *testing.T) {
+	dir := testModule(t)
+
+	resp, err := DefaultDriverResponse(&packages.Config{Mode: goplsMode, Dir: dir}, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(resp.Roots, " ") != "example.com/m/a" {
+		t.Errorf("got roots %q, want [example.com/m/a]", resp.Roots)
+	}
+
+	want := strings.Fields(goCommand(t, dir, "list", "-deps", "-f", "{{.ImportPath}}", "./a"))
+	sort.Strings(want)
+	if got := responseIDs(resp); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got packages %q, want those of go list -deps %q", got, want)
+	}
+
+	for _, pkg := range resp.Packages {
+		if pkg.ID != "example.com/m/a" {
+			continue
+		}
+		if len(pkg.GoFiles) != 1 || pkg.GoFiles[0] != filepath.Join(dir, "a", "a.go") {
+			t.Errorf("got GoFiles %q", pkg.GoFiles)
+		}
+		if pkg.Imports["example.com/m/b"] == nil || pkg.Imports["fmt"] == nil {
+			t.Errorf("got imports %v, want example.com/m/b and fmt", pkg.Imports)
+		}
+		if pkg.Types != nil || pkg.Syntax != nil {
+			t.Error("got types or syntax, which are left to the caller")
+		}
+	}
+
+	if resp.Compiler != "gc" || resp.Arch != goCommand(t, dir, "env", "GOARCH") {
+		t.Errorf("got compiler %s and arch %s", resp.Compiler, resp.Arch)
+	}
+	goVersion := goCommand(t, dir, "env", "GOVERSION")
+	if !strings.HasPrefix(goVersion, "go1."+strconv.Itoa(resp.GoVersion)) {
+		t.Errorf("got go version %d, go env GOVERSION is %s", resp.GoVersion, goVersion)
+	}
+}
+
+// TestDefaultDriverResponseExportData checks that types requested without
+// dependencies are left to be loaded from export data, as go list reports it.
+func TestDefaultDriverResponseExportData(t *testing.T) {
+	dir := testModule(t)
+
+	mode := packages.NeedName | packages.NeedImports | packages.NeedTypes
+	resp, err := DefaultDriverResponse(&packages.Config{Mode: mode, Dir: dir}, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pkg := range resp.Packages {
+		if pkg.ID == "example.com/m/b" && pkg.ExportFile == "" {
+			t.Error("got no export file for example.com/m/b")
+		}
+		if pkg.ID == "example.com/m/a" && len(pkg.CompiledGoFiles) == 0 {
+			t.Error("got no compiled files for example.com/m/a")
+		}
+		if pkg.Types != nil {
+			t.Errorf("got types for %s", pkg.ID)
+		}
+	}
+}
+
+// TestDefaultDriverResponseWithoutDeps checks that requests without NeedDeps
+// list dependencies only when imports are needed, complete as go list -deps
+// reports them rather than as placeholders, and only the roots otherwise.
+func TestDefaultDriverResponseWithoutDeps(t *testing.T) {
+	dir := testModule(t)
+
+	resp, err := DefaultDriverResponse(&packages.Config{Mode: packages.NeedName | packages.NeedFiles, Dir: dir}, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := responseIDs(resp); strings.Join(got, " ") != "example.com/m/a" {
+		t.Errorf("got packages %q without imports, want only the root", got)
+	}
+
+	resp, err = DefaultDriverResponse(&packages.Config{Mode: packages.NeedName | packages.NeedImports, Dir: dir}, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Fields(goCommand(t, dir, "list", "-deps", "-f", "{{.ImportPath}}", "./a"))
+	sort.Strings(want)
+	if got := responseIDs(resp); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got packages %q with imports, want those of go list -deps %q", got, want)
+	}
+	for _, pkg := range resp.Packages {
+		if pkg.Name == "" {
+			t.Errorf("got placeholder package %s", pkg.ID)
+		}
+	}
+}
+
+// TestDefaultDriverResponseTests checks that test variants are returned for
+// file queries when tests are requested.
+func TestDefaultDriverResponseTests(t *testing.T) {
+	dir := testModule(t)
+
+	resp, err := DefaultDriverResponse(&packages.Config{Mode: goplsMode, Dir: dir, Tests: true}, "file="+filepath.Join(dir, "a", "a.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the variants containing the file are roots, not the test main.
+	if got, want := strings.Join(resp.Roots, " "), "example.com/m/a example.com/m/a [example.com/m/a.test]"; got != want {
+		t.Errorf("got roots %q, want %q", got, want)
+	}
+}
@@ -17,6 +17,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -24,15 +25,12 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/cloudwego-contrib/rgo/pkg/consts"
 	"github.com/cloudwego-contrib/rgo/pkg/rlog"
 
 	"github.com/cloudwego-contrib/rgo/pkg/utils"
-
-	"github.com/cloudwego-contrib/rgo/cmd/rgopackagesdriver/internal"
-	"golang.org/x/tools/go/packages"
 )
 
 const (
@@ -40,10 +38,6 @@ const (
 )
 
 type (
-	DefaultPackageLoader struct {
-		Ret *packages.DriverResponse
-	}
-
 	LoadMode int
 
 	DriverRequest struct {
@@ -64,24 +58,7 @@ type (
 	}
 )
 
-var (
-	rgoBasePath string
-
-	_ GoPackagesDriverLoad = (*DefaultPackageLoader)(nil)
-)
-
-type GoPackagesDriverLoad interface {
-	// LoadPackages pkgs is a list of package patterns to load.
-	LoadPackages(cfg *packages.Config, pkgs ...string) []*packages.Package
-}
-
-func (t *DefaultPackageLoader) LoadPackages(cfg *packages.Config, pkgs ...string) []*packages.Package {
-	for _, pkg := range pkgs {
-		p, _ := packages.Load(cfg, pkg)
-		t.Ret.Packages = append(t.Ret.Packages, p...)
-	}
-	return t.Ret.Packages
-}
+var rgoBasePath string
 
 func init() {
 	curWorkPath, err := utils.GetProjectHashPathWithUnderline()
@@ -98,6 +75,28 @@ func main() {
 
 	rlog.InitLogger(filepath.Join(rgoBasePath, consts.LogPath, consts.RGOPackagesDriver), nil)
 
+	if len(os.Args) > 1 && os.Args[1] == consts.DriverServeCommand {
+		path, err := socketPath()
+		if err == nil {
+			err = serve(ctx, path)
+		}
+		if err != nil {
+			rlog.Errorf("driver daemon stopped: %v", err)
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == consts.DriverExplainCommand {
+		// Unlike gopls, people running explain want to know it failed.
+		if err := explain(ctx, os.Stdout, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(ctx, os.Stdin, os.Stdout, os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v", err)
 		// gopls will check the packages driver exit code, and if there is an
@@ -108,62 +107,52 @@ func main() {
 }
 
 func run(ctx context.Context, in io.Reader, out io.Writer, args []string) error {
-	var (
-		targetPkgs []*packages.Package
-		err        error
-	)
-
 	req := &DriverRequest{}
-	if err = json.NewDecoder(in).Decode(&req); err != nil {
+	if err := json.NewDecoder(in).Decode(&req); err != nil {
 		rlog.Errorf("unable to decode driver request: %v", err)
 		return err
 	}
 
-	for k := len(req.Env) - 1; k >= 0; k-- {
-		if strings.Contains(req.Env[k], "GOPACKAGESDRIVER") {
-			req.Env = append(req.Env[:k], req.Env[k+1:]...)
-			break
-		}
+	if os.Getenv(consts.DriverDumpEnv) == "" {
+		return serveRequest(ctx, req, out, args)
 	}
 
-	cfg := &packages.Config{
-		Mode:       packages.LoadMode(req.Mode),
-		Context:    ctx,
-		Env:        req.Env,
-		Overlay:    req.Overlay,
-		Tests:      req.Tests,
-		BuildFlags: req.BuildFlags,
+	var resp bytes.Buffer
+	start := time.Now()
+	err := serveRequest(ctx, req, io.MultiWriter(out, &resp), args)
+	if dumpErr := dumpRequest(args, req, resp.Bytes(), err, time.Since(start)); dumpErr != nil {
+		rlog.Warnf("failed to dump driver request: %v", dumpErr)
 	}
+	return err
+}
 
-	ret, b, err := internal.UnsafeGetDefaultDriverResponse(cfg, args...)
-	if err != nil || b {
-		rlog.Errorf("failed to get default driver response: %v", err)
+// serveRequest writes the response to req for patterns to out, from the daemon
+// if it's running.
+func serveRequest(ctx context.Context, req *DriverRequest, out io.Writer, args []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		rlog.Errorf("failed to get working directory: %v", err)
 		return err
 	}
 
-	for k := len(ret.Packages) - 1; k >= 0; k-- {
-		if len(ret.Packages[k].Errors) > 0 && strings.HasPrefix(ret.Packages[k].PkgPath, "rgo/") {
-			ret.Packages = append(ret.Packages[:k], ret.Packages[k+1:]...)
-		}
+	served := false
+	path, err := socketPath()
+	if err == nil {
+		served, err = runInDaemon(ctx, path, wd, req, args, out)
+	}
+	if served {
+		return err
 	}
-
-	targetPath := filepath.Join(rgoBasePath, "pkg_meta")
-
-	targetPkgs, err = getTargetPackages(targetPath)
 	if err != nil {
-		rlog.Warnf("unable get target packages from path %s: %v", targetPath, err)
+		rlog.Warnf("failed to run in the driver daemon, running in process: %v", err)
 	}
 
-	for _, pkg := range targetPkgs {
-		ret.Roots = append(ret.Roots, pkg.ID)
+	d := newDriver(filepath.Join(rgoBasePath, consts.PkgMetaPath), false)
+	ret, err := d.load(ctx, wd, req, args, nil)
+	if err != nil {
+		return err
 	}
 
-	ret.Packages = append(targetPkgs, ret.Packages...)
-
-	var loader DefaultPackageLoader
-	loader.Ret = ret
-	loader.LoadPackages(cfg, "context", "fmt", "github.com/cloudwego/kitex/client", "github.com/cloudwego/kitex/client/callopt")
-
 	data, err := json.Marshal(ret)
 	if err != nil {
 		rlog.Errorf("json marshal error: %v", err.Error())
@@ -174,44 +163,6 @@ func run(ctx context.Context, in io.Reader, out io.Writer, args []string) error
 	return err
 }
 
-func getTargetPackages(path string) ([]*packages.Package, error) {
-	var results []*packages.Package
-
-	// Check if the path directory exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return results, nil
-	}
-
-	// Read all subdirectories and files under the path directory
-	directories, err := os.ReadDir(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %v", err)
-	}
-
-	// Traverse the first level subdirectory
-	for _, dir := range directories {
-		if dir.IsDir() {
-			jsonFilePath := filepath.Join(path, dir.Name(), "rgo_packages.json")
-
-			if _, err := os.Stat(jsonFilePath); err == nil {
-				data, err := os.ReadFile(jsonFilePath)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read json file %s: %v", jsonFilePath, err)
-				}
-
-				var response []*packages.Package
-				if err := json.Unmarshal(data, &response); err != nil {
-					return nil, fmt.Errorf("failed to parse json file %s: %v", jsonFilePath, err)
-				}
-
-				results = append(results, response...)
-			}
-		}
-	}
-
-	return results, nil
-}
-
 func signalContext(parentCtx context.Context, signals ...os.Signal) (ctx context.Context, stop context.CancelFunc) {
 	ctx, cancel := context.WithCancel(parentCtx)
 	ch := make(chan os.Signal, 1)
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/pkgmeta"
+)
+
+// rgoModules tells the packages of the generated modules apart, from the
+// module paths recorded in their metadata and the project_module they were
+// generated with, which also covers modules without metadata yet.
+type rgoModules struct {
+	paths map[string]bool
+	// prefix and suffix surround $service_name in project_module. Without
+	// $service_name, all the services share the module prefix.
+	prefix, suffix string
+	templated      bool
+}
+
+func newRGOModules(projectModule string, pkgs []*pkgmeta.Entry) *rgoModules {
+	m := &rgoModules{paths: map[string]bool{}}
+
+	if i := strings.Index(projectModule, consts.RGOServiceName); i >= 0 {
+		m.prefix, m.suffix, m.templated = projectModule[:i], projectModule[i+len(consts.RGOServiceName):], true
+	} else {
+		m.prefix = projectModule
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.Module != nil {
+			m.paths[pkg.Module.Path] = true
+		}
+	}
+
+	return m
+}
+
+// contains reports whether pkgPath is the path of a package of a generated
+// module.
+func (m *rgoModules) contains(pkgPath string) bool {
+	for p := pkgPath; p != ""; {
+		if m.paths[p] {
+			return true
+		}
+		i := strings.LastIndex(p, "/")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+
+	if !m.templated {
+		return m.prefix != "" && (pkgPath == m.prefix || strings.HasPrefix(pkgPath, m.prefix+"/"))
+	}
+
+	if !strings.HasPrefix(pkgPath, m.prefix) {
+		return false
+	}
+	rest := pkgPath[len(m.prefix):]
+
+	// Formatted service names have no slash.
+	for i := 1; i <= len(rest) && rest[i-1] != '/'; i++ {
+		if !strings.HasPrefix(rest[i:], m.suffix) {
+			continue
+		}
+		if after := rest[i+len(m.suffix):]; after == "" || strings.HasPrefix(after, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPattern reports whether all the packages pattern can match are in
+// one generated module: it has no ... or only after a path of the module.
+func (m *rgoModules) containsPattern(pattern string) bool {
+	i := strings.Index(pattern, "...")
+	if i < 0 {
+		return m.contains(pattern)
+	}
+
+	literal := pattern[:i]
+	j := strings.LastIndex(literal, "/")
+	if j < 0 {
+		return false
+	}
+	return m.contains(literal[:j])
+}
+
+// imports returns the import paths of pkgs out of the generated modules, sorted.
+func (m *rgoModules) imports(pkgs []*pkgmeta.Entry) []string {
+	seen := map[string]bool{}
+	var paths []string
+
+	for _, pkg := range pkgs {
+		for path := range pkg.Imports {
+			if !seen[path] && !m.contains(path) {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	sort.Strings(paths)
+	return paths
+}
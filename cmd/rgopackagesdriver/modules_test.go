@@ -0,0 +1,112 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/pkgmeta"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestRGOModulesContains(t *testing.T) {
+	meta := []*pkgmeta.Entry{{
+		ID:      "corp.io/legacy/echo",
+		PkgPath: "corp.io/legacy/echo",
+		Module:  &packages.Module{Path: "corp.io/legacy/echo"},
+	}}
+
+	for _, tc := range []struct {
+		projectModule string
+		in            []string
+		out           []string
+	}{
+		{
+			projectModule: "rgo/$service_name",
+			in:            []string{"rgo/echo", "rgo/echo/kitex_gen/echo", "corp.io/legacy/echo/kitex_gen"},
+			out:           []string{"rgo", "github.com/cloudwego/kitex/client", "corp.io/legacy"},
+		},
+		{
+			projectModule: "example.com/gen/$service_name",
+			in:            []string{"example.com/gen/a_b_c", "example.com/gen/a_b_c/kitex_gen/x"},
+			out:           []string{"rgo/echo", "example.com/gen", "example.com/generated/x"},
+		},
+		{
+			projectModule: "example.com/$service_name/client",
+			in:            []string{"example.com/echo/client", "example.com/echo/client/kitex_gen"},
+			out:           []string{"example.com/echo", "example.com/echo/clients", "example.com/client"},
+		},
+		{
+			projectModule: "example.com/gen",
+			in:            []string{"example.com/gen", "example.com/gen/echo"},
+			out:           []string{"example.com/generated", "rgo/echo"},
+		},
+	} {
+		m := newRGOModules(tc.projectModule, meta)
+		for _, path := range tc.in {
+			if !m.contains(path) {
+				t.Errorf("%s: %s isn't contained", tc.projectModule, path)
+			}
+		}
+		for _, path := range tc.out {
+			if m.contains(path) {
+				t.Errorf("%s: %s is contained", tc.projectModule, path)
+			}
+		}
+	}
+}
+
+func TestRGOModulesContainsPattern(t *testing.T) {
+	m := newRGOModules("example.com/gen/$service_name", nil)
+
+	for pattern, want := range map[string]bool{
+		"example.com/gen/echo":       true,
+		"example.com/gen/echo/...":   true,
+		"example.com/gen/echo/k...":  true,
+		"example.com/gen/...":        false,
+		"example.com/...":            false,
+		"...":                        false,
+		"example.com/app/internal/x": false,
+	} {
+		if got := m.containsPattern(pattern); got != want {
+			t.Errorf("containsPattern(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
+
+func TestRGOModulesImports(t *testing.T) {
+	m := newRGOModules("rgo/$service_name", nil)
+
+	pkgs := []*pkgmeta.Entry{
+		{ID: "rgo/echo", Imports: map[string]string{
+			"context":                           "context",
+			"rgo/echo/kitex_gen/echo":           "rgo/echo/kitex_gen/echo",
+			"github.com/cloudwego/kitex/client": "github.com/cloudwego/kitex/client",
+		}},
+		{ID: "rgo/echo/kitex_gen/echo", Imports: map[string]string{
+			"context":                                "context",
+			"github.com/apache/thrift/lib/go/thrift": "github.com/apache/thrift/lib/go/thrift",
+		}},
+	}
+
+	got := strings.Join(m.imports(pkgs), " ")
+	want := "context github.com/apache/thrift/lib/go/thrift github.com/cloudwego/kitex/client"
+	if got != want {
+		t.Errorf("got imports %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego-contrib/rgo/pkg/pkgmeta"
+	"golang.org/x/tools/go/packages"
+)
+
+// packageQuery matches the patterns of a driver request against the rgo
+// packages read from pkg_meta.
+type packageQuery struct {
+	// dir is the directory relative patterns are resolved against.
+	dir     string
+	pkgs    []*pkgmeta.Entry
+	byID    map[string]*pkgmeta.Entry
+	modules *rgoModules
+	// testsOf maps the paths of packages to the IDs of their test packages,
+	// "p [p.test]" and "p_test [p.test]", as the go command returns them for
+	// the patterns matching p.
+	testsOf map[string][]string
+}
+
+// newPackageQuery returns a query of pkgs, without their test variants unless
+// tests are asked for.
+func newPackageQuery(dir string, pkgs []*pkgmeta.Entry, modules *rgoModules, tests bool) *packageQuery {
+	q := &packageQuery{
+		dir:     dir,
+		byID:    make(map[string]*pkgmeta.Entry, len(pkgs)),
+		modules: modules,
+		testsOf: map[string][]string{},
+	}
+	for _, pkg := range pkgs {
+		if pkg.ForTest != "" && !tests {
+			continue
+		}
+		q.pkgs = append(q.pkgs, pkg)
+		q.byID[pkg.ID] = pkg
+		if isTestOf(pkg) {
+			q.testsOf[pkg.ForTest] = append(q.testsOf[pkg.ForTest], pkg.ID)
+		}
+	}
+	return q
+}
+
+// match returns the IDs of the rgo packages matching pattern, and whether
+// pattern can only match rgo packages, in which case the default driver
+// needn't load it.
+func (q *packageQuery) match(pattern string) (ids []string, handled bool) {
+	pattern = strings.TrimPrefix(pattern, "pattern=")
+
+	if strings.HasPrefix(pattern, "file=") {
+		file := q.abs(strings.TrimPrefix(pattern, "file="))
+		for _, entry := range q.pkgs {
+			// Only the packages of the directory of file are decoded, and
+			// its packages built for the tests of others aren't returned.
+			if entry.Dir != filepath.Dir(file) || (entry.ForTest != "" && !isTestOf(entry)) {
+				continue
+			}
+			if pkg, err := entry.Package(); err == nil && containsFile(pkg, file) {
+				ids = append(ids, pkg.ID)
+			}
+		}
+		return ids, len(ids) > 0
+	}
+
+	if isDirPattern(pattern) {
+		matchDir := matchPattern(filepath.ToSlash(q.abs(pattern)))
+		for _, pkg := range q.pkgs {
+			if pkg.ForTest == "" && pkg.Dir != "" && matchDir(filepath.ToSlash(pkg.Dir)) {
+				ids = append(ids, pkg.ID)
+				ids = append(ids, q.testsOf[pkg.PkgPath]...)
+			}
+		}
+		return ids, len(ids) > 0 && !strings.Contains(pattern, "...")
+	}
+
+	matchPath := matchPattern(pattern)
+	for _, pkg := range q.pkgs {
+		if pkg.ForTest == "" && matchPath(pkg.PkgPath) {
+			ids = append(ids, pkg.ID)
+			ids = append(ids, q.testsOf[pkg.PkgPath]...)
+		}
+	}
+	return ids, q.modules.containsPattern(pattern) || (len(ids) > 0 && !strings.Contains(pattern, "..."))
+}
+
+// closure returns the rgo packages of roots, those imported by pkgs and,
+// transitively, the rgo packages they import, in the order of the metadata.
+func (q *packageQuery) closure(roots []string, pkgs []*packages.Package) []*pkgmeta.Entry {
+	seen := map[string]bool{}
+	var queue []string
+
+	visit := func(id string) {
+		if _, ok := q.byID[id]; ok && !seen[id] {
+			seen[id] = true
+			queue = append(queue, id)
+		}
+	}
+
+	for _, id := range roots {
+		visit(id)
+	}
+	for _, pkg := range pkgs {
+		for _, imp := range pkg.Imports {
+			visit(imp.ID)
+		}
+	}
+	for len(queue) > 0 {
+		pkg := q.byID[queue[0]]
+		queue = queue[1:]
+		for _, id := range pkg.Imports {
+			visit(id)
+		}
+	}
+
+	var result []*pkgmeta.Entry
+	for _, pkg := range q.pkgs {
+		if seen[pkg.ID] {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
+
+func (q *packageQuery) abs(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(q.dir, path)
+	}
+	return filepath.Clean(path)
+}
+
+// isTestOf reports whether pkg is a test package of the package under test,
+// rather than a package it imports built again for the test.
+func isTestOf(pkg *pkgmeta.Entry) bool {
+	return pkg.ForTest != "" && (pkg.PkgPath == pkg.ForTest || pkg.PkgPath == pkg.ForTest+"_test")
+}
+
+func containsFile(pkg *packages.Package, file string) bool {
+	for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.OtherFiles} {
+		for _, f := range files {
+			if filepath.Clean(f) == file {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isDirPattern reports whether pattern names directories rather than import
+// paths, the way the go command tells them apart.
+func isDirPattern(pattern string) bool {
+	return pattern == "." || pattern == ".." ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../") ||
+		filepath.IsAbs(pattern)
+}
+
+// matchPattern returns a function reporting whether a path matches pattern,
+// where ... matches any string and a trailing /... also matches the path
+// before it, as in the go command.
+func matchPattern(pattern string) func(string) bool {
+	re := regexp.QuoteMeta(pattern)
+	if strings.HasSuffix(re, `/\.\.\.`) {
+		re = strings.TrimSuffix(re, `/\.\.\.`) + `(/\.\.\.)?`
+	}
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+
+	reg := regexp.MustCompile(`^` + re + `$`)
+	return reg.MatchString
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/pkgmeta"
+	"golang.org/x/tools/go/packages"
+)
+
+func testQuery(t *testing.T) (*packageQuery, string) {
+	cache := filepath.Join(t.TempDir(), "repo")
+
+	pkg := func(path string, imports ...string) *packages.Package {
+		p := &packages.Package{
+			ID:      path,
+			PkgPath: path,
+			GoFiles: []string{filepath.Join(cache, filepath.FromSlash(path), "f.go")},
+			Imports: map[string]*packages.Package{},
+		}
+		for _, imp := range imports {
+			p.Imports[imp] = &packages.Package{ID: imp}
+		}
+		return p
+	}
+
+	pkgs := []*packages.Package{
+		pkg("rgo/echo", "rgo/echo/kitex_gen/echo", "github.com/cloudwego/kitex/client"),
+		pkg("rgo/echo/kitex_gen/echo", "rgo/base/kitex_gen/base"),
+		pkg("rgo/base/kitex_gen/base"),
+		pkg("rgo/calc"),
+	}
+	path := filepath.Join(t.TempDir(), consts.PkgMetaFile)
+	if err := pkgmeta.Write(path, pkgmeta.Header{}, pkgs); err != nil {
+		t.Fatal(err)
+	}
+	file, err := pkgmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newPackageQuery(filepath.Join(cache, "rgo"), file.Entries, newRGOModules(consts.RGODefaultModuleName, file.Entries), false), cache
+}
+
+func TestPackageQueryMatch(t *testing.T) {
+	q, cache := testQuery(t)
+
+	for _, tc := range []struct {
+		pattern string
+		ids     string
+		handled bool
+	}{
+		{"rgo/echo", "rgo/echo", true},
+		{"rgo/echo/...", "rgo/echo rgo/echo/kitex_gen/echo", true},
+		{"rgo/missing", "", true},
+		{"pattern=rgo/calc", "rgo/calc", true},
+		{"file=" + filepath.Join(cache, "rgo", "calc", "f.go"), "rgo/calc", true},
+		{"file=" + filepath.Join(cache, "main.go"), "", false},
+		{"./echo/kitex_gen/...", "rgo/echo/kitex_gen/echo", false},
+		{"./calc", "rgo/calc", true},
+		{"./...", "rgo/echo rgo/echo/kitex_gen/echo rgo/base/kitex_gen/base rgo/calc", false},
+		{"github.com/cloudwego/kitex/...", "", false},
+		{"fmt", "", false},
+	} {
+		ids, handled := q.match(tc.pattern)
+		if strings.Join(ids, " ") != tc.ids || handled != tc.handled {
+			t.Errorf("match(%q) = %q, %v, want %q, %v", tc.pattern, ids, handled, tc.ids, tc.handled)
+		}
+	}
+}
+
+func TestPackageQueryClosure(t *testing.T) {
+	q, _ := testQuery(t)
+
+	var ids []string
+	for _, pkg := range q.closure([]string{"rgo/echo"}, nil) {
+		ids = append(ids, pkg.ID)
+	}
+	if got, want := strings.Join(ids, " "), "rgo/echo rgo/echo/kitex_gen/echo rgo/base/kitex_gen/base"; got != want {
+		t.Errorf("closure of rgo/echo = %q, want %q", got, want)
+	}
+
+	user := &packages.Package{ID: "example.com/app", Imports: map[string]*packages.Package{"rgo/calc": {ID: "rgo/calc"}}}
+	ids = nil
+	for _, pkg := range q.closure(nil, []*packages.Package{user}) {
+		ids = append(ids, pkg.ID)
+	}
+	if got, want := strings.Join(ids, " "), "rgo/calc"; got != want {
+		t.Errorf("closure of the imports of example.com/app = %q, want %q", got, want)
+	}
+}
+
+func TestPackageQueryTests(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "repo", "rgo", "echo")
+	pkg := func(id, path, file string, imports ...string) *packages.Package {
+		p := &packages.Package{ID: id, PkgPath: path, GoFiles: []string{filepath.Join(dir, file)}, Imports: map[string]*packages.Package{}}
+		for _, imp := range imports {
+			p.Imports[strings.Fields(imp)[0]] = &packages.Package{ID: imp}
+		}
+		return p
+	}
+
+	path := filepath.Join(t.TempDir(), consts.PkgMetaFile)
+	err := pkgmeta.Write(path, pkgmeta.Header{}, []*packages.Package{
+		pkg("rgo/echo", "rgo/echo", "echo.go"),
+		pkg("rgo/echo [rgo/echo.test]", "rgo/echo", "echo_test.go"),
+		pkg("rgo/echo_test [rgo/echo.test]", "rgo/echo_test", "x_test.go", "rgo/echo [rgo/echo.test]", "rgo/echo/sub [rgo/echo.test]"),
+		pkg("rgo/echo/sub [rgo/echo.test]", "rgo/echo/sub", "sub/sub.go", "rgo/echo [rgo/echo.test]"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := pkgmeta.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		pattern string
+		tests   bool
+		ids     string
+	}{
+		{"rgo/echo", false, "rgo/echo"},
+		{"rgo/echo", true, "rgo/echo rgo/echo [rgo/echo.test] rgo/echo_test [rgo/echo.test]"},
+		{"rgo/echo/...", true, "rgo/echo rgo/echo [rgo/echo.test] rgo/echo_test [rgo/echo.test]"},
+		{"file=" + filepath.Join(dir, "x_test.go"), true, "rgo/echo_test [rgo/echo.test]"},
+		{"file=" + filepath.Join(dir, "x_test.go"), false, ""},
+	} {
+		q := newPackageQuery(dir, file.Entries, newRGOModules(consts.RGODefaultModuleName, file.Entries), tc.tests)
+		if ids, _ := q.match(tc.pattern); strings.Join(ids, " ") != tc.ids {
+			t.Errorf("match(%q) with tests %v = %q, want %q", tc.pattern, tc.tests, ids, tc.ids)
+		}
+	}
+
+	q := newPackageQuery(dir, file.Entries, newRGOModules(consts.RGODefaultModuleName, file.Entries), true)
+	var ids []string
+	for _, entry := range q.closure([]string{"rgo/echo_test [rgo/echo.test]"}, nil) {
+		ids = append(ids, entry.ID)
+	}
+	if got, want := strings.Join(ids, ", "), "rgo/echo [rgo/echo.test], rgo/echo_test [rgo/echo.test], rgo/echo/sub [rgo/echo.test]"; got != want {
+		t.Errorf("closure of the external test = %q, want %q", got, want)
+	}
+}
@@ -14,6 +14,7 @@ require (
 	golang.org/x/text v0.13.0
 	golang.org/x/tools v0.18.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -45,7 +46,6 @@ require (
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace github.com/TobiasYin/go-lsp v0.0.0-20231106040121-c84e66f01aa4 => github.com/violapioggia/go-lsp v0.0.0-20240916090506-d0b28bdca26b
@@ -84,7 +84,10 @@ export async function startRgoLspServer() {
   };
 
   const clientOptions: LanguageClientOptions = {
-    documentSelector: [{ scheme: "file", language: "go" }],
+    documentSelector: [
+      { scheme: "file", language: "go" },
+      { scheme: "file", language: "yaml", pattern: "**/rgo_config.yaml" },
+    ],
     synchronize: {
       fileEvents: vscode.workspace.createFileSystemWatcher("**/.clientrc"),
     },
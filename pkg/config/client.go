@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Resolver is a service discovery resolver that can be named by the client
+// block of an IDL.
+type Resolver struct {
+	Alias      string // Package alias used in the generated code (e.g., dns)
+	ImportPath string // Import path of the package providing the resolver
+	New        string // Go expression creating the resolver
+}
+
+var Resolvers = map[string]Resolver{
+	"dns": {Alias: "dns", ImportPath: "github.com/kitex-contrib/resolver-dns", New: "dns.NewDNSResolver()"},
+}
+
+// ClientTransports are the transport protocols accepted by the client block.
+var ClientTransports = []string{"purepayload", "ttheader", "framed", "ttheader_framed", "grpc"}
+
+func (c *Client) Validate() error {
+	if len(c.HostPorts) > 0 && c.Resolver != "" {
+		return fmt.Errorf("hostports and resolver can't be both set")
+	}
+
+	for _, d := range []string{c.RPCTimeout, c.ConnectTimeout} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("invalid timeout %q: %v", d, err)
+		}
+	}
+
+	if c.Transport != "" && !contains(ClientTransports, c.Transport) {
+		return fmt.Errorf("unknown transport %q, expected one of %v", c.Transport, ClientTransports)
+	}
+
+	if _, ok := Resolvers[c.Resolver]; c.Resolver != "" && !ok {
+		return fmt.Errorf("unknown resolver %q", c.Resolver)
+	}
+
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// Middleware is a Kitex client middleware: Func, declared in the package at
+// ImportPath, must be an endpoint.Middleware.
+type Middleware struct {
+	Name       string `yaml:"name" mapstructure:"name"`
+	ImportPath string `yaml:"import_path" mapstructure:"import_path"`
+	Func       string `yaml:"func" mapstructure:"func"`
+}
+
+func (m *Middleware) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("middleware without name")
+	}
+
+	if m.ImportPath == "" {
+		return fmt.Errorf("middleware %s without import_path", m.Name)
+	}
+
+	if !token.IsIdentifier(m.Func) || !token.IsExported(m.Func) {
+		return fmt.Errorf("middleware %s has invalid func %q, expected an exported function name", m.Name, m.Func)
+	}
+
+	return nil
+}
+
+// Ref returns the reference to m passed on to the rgo plugin, the import path
+// followed by the function (e.g., github.com/acme/mw.Auth).
+func (m *Middleware) Ref() string {
+	return m.ImportPath + "." + m.Func
+}
+
+// ParseMiddlewareRef is the reverse of Middleware.Ref. Function names have no
+// dot, so the reference is split at its last one.
+func ParseMiddlewareRef(ref string) (Middleware, error) {
+	i := strings.LastIndex(ref, ".")
+	if i <= 0 {
+		return Middleware{}, fmt.Errorf("invalid middleware %q, expected <import path>.<func>", ref)
+	}
+
+	m := Middleware{Name: ref, ImportPath: ref[:i], Func: ref[i+1:]}
+	if err := m.Validate(); err != nil {
+		return Middleware{}, err
+	}
+
+	return m, nil
+}
+
+// MiddlewareChain returns the middlewares applied to the clients of an IDL:
+// the global ones, then those of the IDL. An IDL middleware named after a
+// global one replaces it in place.
+func MiddlewareChain(global, idl []Middleware) []Middleware {
+	chain := append([]Middleware(nil), global...)
+
+	for _, m := range idl {
+		replaced := false
+		for i := range chain {
+			if chain[i].Name == m.Name {
+				chain[i] = m
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			chain = append(chain, m)
+		}
+	}
+
+	return chain
+}
+
+func validateMiddlewares(middlewares []Middleware) error {
+	names := map[string]bool{}
+	for i := range middlewares {
+		if err := middlewares[i].Validate(); err != nil {
+			return err
+		}
+		if names[middlewares[i].Name] {
+			return fmt.Errorf("duplicate middleware %s", middlewares[i].Name)
+		}
+		names[middlewares[i].Name] = true
+	}
+
+	return nil
+}
@@ -16,6 +16,12 @@
 
 package config
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
 type IDLRepo struct {
 	RepoName string `yaml:"repo_name" mapstructure:"repo_name"`
 	GitUrl   string `yaml:"git_url" mapstructure:"git_url"`
@@ -28,6 +34,39 @@ type IDL struct {
 	FormatServiceName string
 	IDLPath           string `yaml:"idl_path" mapstructure:"idl_path"`
 	RepoName          string `yaml:"repo_name" mapstructure:"repo_name"`
+	Mode              string `yaml:"mode" mapstructure:"mode"`
+	Protocol          string `yaml:"protocol" mapstructure:"protocol"`
+	Mock              bool   `yaml:"mock" mapstructure:"mock"`
+	Server            bool   `yaml:"server" mapstructure:"server"`
+	Client            Client `yaml:"client" mapstructure:"client"`
+
+	Middlewares []Middleware `yaml:"middlewares" mapstructure:"middlewares"`
+	// MiddlewareChain is set by ReadConfig from the global middlewares and Middlewares.
+	MiddlewareChain []Middleware `yaml:"-" mapstructure:"-"`
+}
+
+// Client holds the options of the default client generated for an IDL. They
+// can be overridden at runtime by the RGO_<SERVICE>_* environment variables.
+type Client struct {
+	HostPorts      []string `yaml:"hostports" mapstructure:"hostports"`
+	RPCTimeout     string   `yaml:"rpc_timeout" mapstructure:"rpc_timeout"`
+	ConnectTimeout string   `yaml:"connect_timeout" mapstructure:"connect_timeout"`
+	Transport      string   `yaml:"transport" mapstructure:"transport"`
+	Resolver       string   `yaml:"resolver" mapstructure:"resolver"`
+}
+
+// Hook is a single step run around code generation. Either Command (run by the
+// shell) or Plugin (the name of a hook registered in Go) must be set.
+type Hook struct {
+	Name    string `yaml:"name" mapstructure:"name"`
+	Command string `yaml:"command" mapstructure:"command"`
+	Plugin  string `yaml:"plugin" mapstructure:"plugin"`
+}
+
+type Hooks struct {
+	PreFetch    []Hook `yaml:"pre_fetch" mapstructure:"pre_fetch"`
+	PostService []Hook `yaml:"post_service" mapstructure:"post_service"`
+	PostRun     []Hook `yaml:"post_run" mapstructure:"post_run"`
 }
 
 type RGOConfig struct {
@@ -35,4 +74,20 @@ type RGOConfig struct {
 	ProjectModule string    `yaml:"project_module" mapstructure:"project_module"`
 	IDLRepos      []IDLRepo `yaml:"idl_repos" mapstructure:"idl_repos"`
 	IDLs          []IDL     `yaml:"idls" mapstructure:"idls"`
+	Hooks         Hooks     `yaml:"hooks" mapstructure:"hooks"`
+
+	// Middlewares are applied to the clients of every IDL.
+	Middlewares []Middleware `yaml:"middlewares" mapstructure:"middlewares"`
+}
+
+// IDLHash returns a hash of the configuration the module of idl is generated
+// with, which changes whenever the module may.
+func (c *RGOConfig) IDLHash(idl IDL) string {
+	data, _ := json.Marshal(struct {
+		ProjectModule string
+		IDL           IDL
+	}{c.ProjectModule, idl})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
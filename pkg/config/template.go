@@ -19,9 +19,85 @@ package config
 import "github.com/cloudwego/thriftgo/parser"
 
 type RGOClientTemplateData struct {
-	RGOModuleName     string   // Name of the RGO module (e.g., rgo)
-	ServiceName       string   // Name of the service (e.g., service.one)
-	FormatServiceName string   // Formatted service name (e.g., service_one)
-	Imports           []string // List of imports required for the client (e.g., context, github.com/cloudwego/kitex/client)
+	RGOModuleName     string      // Name of the RGO module (e.g., rgo)
+	ServiceName       string      // Name of the service (e.g., service.one)
+	FormatServiceName string      // Formatted service name (e.g., service_one)
+	Imports           []string    // List of imports required for the client (e.g., context, github.com/cloudwego/kitex/client)
+	PkgImports        []RGOImport // kitex_gen packages referenced by the method signatures
+	Service           *RGOService // The service the client is generated for, resolved the way Kitex resolves it
+	Client            Client      // Options of the default client from the client block of the IDL
+	ClientEnvPrefix   string      // Prefix of the environment variables overriding the default client options (e.g., RGO_SERVICE_ONE_)
+	Resolver          *Resolver   // Resolver named by the client block, if any
+	Generic           bool        // Whether the client uses Kitex's JSON generic call instead of kitex_gen
+	HTTP              bool        // Whether the client calls the HTTP routes of the api annotations instead of Kitex
+	IDLPath           string      // Path of the IDL loaded at runtime by the generic client
+	MiddlewareImports []RGOImport // Packages providing Middlewares
+	Middlewares       []string    // Middlewares applied to the clients, in chain order (e.g., mw.Auth)
 	*parser.Thrift
 }
+
+type RGOImport struct {
+	Alias        string // Package alias used in the generated code (e.g., base)
+	Path         string // Import path (e.g., rgo/service_one/kitex_gen/base)
+	InSignatures bool   // Whether the method signatures reference the package
+	InExceptions bool   // Whether the exception types reference the package
+	InHandler    bool   // Whether the handler interface references the package
+}
+
+type RGOService struct {
+	Name              string          // Go name of the service (e.g., Hello)
+	PkgRefName        string          // Alias of the kitex_gen package defining the service (e.g., hello)
+	ServiceImportPath string          // Import path of the Kitex client package of the service (e.g., rgo/service_one/kitex_gen/hello/hello)
+	ServiceRefName    string          // Alias of the Kitex client package of the service, unique among the imports (e.g., hello)
+	Methods           []*RGOMethod    // Functions of the service, in IDL order
+	Exceptions        []*RGOException // Exceptions thrown by any function of the service, without duplicates
+	HasUnary          bool            // Whether any method is called through the Kitex Client
+	HasStreaming      bool            // Whether any method is called through the Kitex StreamClient
+	Doc               []string        // Lines of the doc comment, from the IDL comments of the service and its position
+}
+
+type RGOMethod struct {
+	Name      string          // Go name of the method in the generated client (e.g., Echo), KitexName unless it collides
+	KitexName string          // Go name of the method in kitex_gen (e.g., Echo)
+	RawName   string          // Name of the function in the IDL (e.g., echo)
+	Args      []*RGOParameter // Arguments, in IDL order
+	Resp      string          // Go type of the response (e.g., *hello.Response), empty for void functions
+	Void      bool            // Whether the function returns void
+	Oneway    bool            // Whether the function is oneway
+	Doc       []string        // Lines of the doc comment, from the IDL comments of the function, its arguments and its position
+
+	Streaming       string // Streaming mode from the streaming.mode annotation (e.g., bidirectional), empty for non-streaming functions
+	ClientStreaming bool   // Whether the client sends a stream of requests
+	ServerStreaming bool   // Whether the server sends a stream of responses
+	StreamType      string // Name of the stream interface returned by client or server streaming methods (e.g., Hello_EchoClient)
+
+	HandlerStreamType string // kitex_gen type of the stream passed to the handler of client or server streaming methods (e.g., hello.Hello_EchoServer)
+
+	Route *RGOHTTPRoute // Route of the method from its api annotations, set for HTTP clients only
+}
+
+type RGOHTTPRoute struct {
+	Method  string          // HTTP method (e.g., GET)
+	Path    string          // Go expression of the request path, the route with its parameters filled in (e.g., "/users/" + url.PathEscape(fmt.Sprint(req.ID)))
+	Query   []*RGOHTTPParam // Query parameters from the api.query annotations
+	Headers []*RGOHTTPParam // Headers from the api.header annotations
+	Body    bool            // Whether the request is sent as a JSON body
+}
+
+type RGOHTTPParam struct {
+	Name     string // Name of the query parameter or header (e.g., page)
+	Field    string // Go expression of the request field (e.g., req.Page)
+	Value    string // Go expression of the string sent for the field, or for each of its elements v if it is a list
+	Optional bool   // Whether the field is a pointer, sent only when set
+	List     bool   // Whether the field is a list, sent once per element
+}
+
+type RGOException struct {
+	Name string // Name of the exception in the generated client package (e.g., NotFound)
+	Type string // Go type of the exception, without pointer (e.g., hello.NotFound)
+}
+
+type RGOParameter struct {
+	Name string // Go name of the parameter (e.g., req)
+	Type string // Go type of the parameter (e.g., *hello.Request)
+}
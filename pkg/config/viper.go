@@ -39,9 +39,40 @@ func ReadConfig(path string) (*RGOConfig, error) {
 		log.Fatalf("Failed to parse config into struct: %v", err)
 	}
 
+	if err := validateMiddlewares(c.Middlewares); err != nil {
+		return nil, fmt.Errorf("invalid middlewares: %v", err)
+	}
+
 	for i := range c.IDLs {
-		c.IDLs[i].FormatServiceName = strings.ReplaceAll(c.IDLs[i].ServiceName, "-", "_")
-		c.IDLs[i].FormatServiceName = strings.ReplaceAll(c.IDLs[i].FormatServiceName, ".", "_")
+		c.IDLs[i].FormatServiceName = FormatServiceName(c.IDLs[i].ServiceName)
+
+		if c.IDLs[i].Mode != "" && c.IDLs[i].Mode != consts.IDLModeGeneric {
+			return nil, fmt.Errorf("unsupported mode %q of %s", c.IDLs[i].Mode, c.IDLs[i].ServiceName)
+		}
+
+		if c.IDLs[i].Server && c.IDLs[i].Mode == consts.IDLModeGeneric {
+			return nil, fmt.Errorf("server of %s can't be generated in the generic mode", c.IDLs[i].ServiceName)
+		}
+
+		if err := c.IDLs[i].Client.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid client of %s: %v", c.IDLs[i].ServiceName, err)
+		}
+
+		if err := validateMiddlewares(c.IDLs[i].Middlewares); err != nil {
+			return nil, fmt.Errorf("invalid middlewares of %s: %v", c.IDLs[i].ServiceName, err)
+		}
+
+		switch c.IDLs[i].Protocol {
+		case "", consts.IDLProtocolRPC:
+			c.IDLs[i].MiddlewareChain = MiddlewareChain(c.Middlewares, c.IDLs[i].Middlewares)
+		case consts.IDLProtocolHTTP:
+			// Middlewares are Kitex ones, the global ones don't apply to HTTP clients.
+			if err := validateHTTPIDL(&c.IDLs[i]); err != nil {
+				return nil, fmt.Errorf("invalid http idl %s: %v", c.IDLs[i].ServiceName, err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported protocol %q of %s", c.IDLs[i].Protocol, c.IDLs[i].ServiceName)
+		}
 	}
 
 	if c.ProjectModule == "" {
@@ -51,6 +82,30 @@ func ReadConfig(path string) (*RGOConfig, error) {
 	return c, nil
 }
 
+// FormatServiceName returns the name of the module and directories generated
+// for the service named serviceName.
+func FormatServiceName(serviceName string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(serviceName, "-", "_"), ".", "_")
+}
+
+// ReadProjectModule returns the effective project_module of the config at
+// path, leaving the rest of it unread and unvalidated.
+func ReadProjectModule(path string) (string, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return "", fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+
+	module := v.GetString("project_module")
+	if module == "" {
+		module = consts.RGODefaultModuleName
+	}
+
+	return module, nil
+}
+
 func RewriteRGOConfig(key string, value interface{}) error {
 	viper.Set(key, value)
 
@@ -61,3 +116,21 @@ func RewriteRGOConfig(key string, value interface{}) error {
 
 	return nil
 }
+
+// validateHTTPIDL rejects the options of idl that only apply to Kitex clients.
+func validateHTTPIDL(idl *IDL) error {
+	switch {
+	case idl.Mode == consts.IDLModeGeneric:
+		return fmt.Errorf("the generic mode is for Kitex clients")
+	case idl.Server:
+		return fmt.Errorf("servers are generated for Kitex only")
+	case len(idl.Middlewares) > 0:
+		return fmt.Errorf("middlewares are Kitex middlewares")
+	case idl.Client.Transport != "":
+		return fmt.Errorf("transport is a Kitex option")
+	case idl.Client.Resolver != "":
+		return fmt.Errorf("resolver is a Kitex option")
+	}
+
+	return nil
+}
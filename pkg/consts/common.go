@@ -18,6 +18,7 @@ package consts
 
 const (
 	RGOConfigPath = "./rgo_config.yaml"
+	RGOConfigFile = "rgo_config.yaml"
 
 	RGOBasePath = ".rgo/cache"
 	IDLPath     = "idl"
@@ -25,8 +26,17 @@ const (
 	RepoPath    = "repo"
 	PkgMetaPath = "pkg_meta"
 	BuildPath   = "build"
+
+	ProjectMarkerFile = "project_path"
+
+	// PkgMetaFile holds the package metadata of a service in pkg_meta, in the
+	// format of pkg/pkgmeta. LegacyPkgMetaFile held it in JSON before.
+	PkgMetaFile       = "rgo_packages.meta"
+	LegacyPkgMetaFile = "rgo_packages.json"
 )
 
+const RGOVersion = "0.0.1"
+
 const (
 	RGODefaultModuleName = "rgo/" + RGOServiceName
 	RGOServiceName       = "$service_name"
@@ -44,3 +54,30 @@ const (
 	GoWorkMode           = "gowork"
 	GoPackagesDriverMode = "gopackagesdriver"
 )
+
+const (
+	// IDLModeGeneric generates a client calling the service with Kitex's JSON generic call,
+	// which loads the IDL at runtime instead of compiling kitex_gen.
+	IDLModeGeneric = "generic"
+)
+
+const (
+	// IDLProtocolRPC calls the service with Kitex, the default.
+	IDLProtocolRPC = "rpc"
+	// IDLProtocolHTTP calls the service over HTTP, with the routes of the api annotations
+	// of its functions (e.g., api.get="/users/:id"), the way Hertz serves them.
+	IDLProtocolHTTP = "http"
+)
+
+const (
+	HookStagePreFetch    = "pre_fetch"
+	HookStagePostService = "post_service"
+	HookStagePostRun     = "post_run"
+
+	HookStageEnv       = "RGO_HOOK_STAGE"
+	HookServiceNameEnv = "RGO_SERVICE_NAME"
+	HookModulePathEnv  = "RGO_MODULE_PATH"
+	HookOutputDirEnv   = "RGO_OUTPUT_DIR"
+
+	ClientEnvPrefix = "RGO_"
+)
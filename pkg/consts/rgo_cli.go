@@ -27,6 +27,19 @@ const (
 	ServiceNameFlag        = "service_name"
 	FormatServiceNameFlag  = "format_service_name"
 	IDLPathFlag            = "idl_path"
+	MockFlag               = "mock"
+	ServerFlag             = "server"
+	IDLModeFlag            = "idl_mode"
+	ProtocolFlag           = "protocol"
+	MaxAgeFlag             = "max_age"
+	MaxSizeFlag            = "max_size"
+
+	ClientHostPortsFlag      = "client_hostports"
+	ClientRPCTimeoutFlag     = "client_rpc_timeout"
+	ClientConnectTimeoutFlag = "client_connect_timeout"
+	ClientTransportFlag      = "client_transport"
+	ClientResolverFlag       = "client_resolver"
+	MiddlewaresFlag          = "middlewares"
 )
 
 const (
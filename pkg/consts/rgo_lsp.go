@@ -30,6 +30,8 @@ const (
 	MethodRGOWindowShowWarn  = "custom/rgo/window_show_warn"
 	MethodRGOWindowShowError = "custom/rgo/window_show_error"
 	MethodRGOProgress        = "custom/rgo/progress"
+
+	MethodPublishDiagnostics = "textDocument/publishDiagnostics"
 )
 
 const (
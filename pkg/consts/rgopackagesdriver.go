@@ -16,6 +16,28 @@
 
 package consts
 
+import "time"
+
 const (
 	RGOPackagesDriver = "rgo_packages_driver"
+
+	// DriverServeCommand runs the driver as a daemon serving the requests of
+	// the driver processes of the project over a Unix socket.
+	DriverServeCommand = "serve"
+	// DriverDaemonEnv set to "on" makes the driver start the daemon of the
+	// project when it isn't running.
+	DriverDaemonEnv = "RGO_PACKAGES_DRIVER_DAEMON"
+	// DriverDaemonIdleTimeout is how long the daemon runs without requests.
+	DriverDaemonIdleTimeout = 30 * time.Minute
+	// DriverSocketDir holds the daemon sockets in the runtime directory of
+	// the user, or else in their cache directory.
+	DriverSocketDir = "rgo"
+
+	// DriverExplainCommand prints the response of the driver to patterns,
+	// with the origin of each package and timings.
+	DriverExplainCommand = "explain"
+	// DriverDumpEnv set to any value makes the driver dump every request and
+	// its response to DriverDumpPath in its log directory.
+	DriverDumpEnv  = "RGO_PACKAGES_DRIVER_DUMP"
+	DriverDumpPath = "dump"
 )
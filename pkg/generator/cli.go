@@ -21,7 +21,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/cloudwego-contrib/rgo/pkg/config"
 	"github.com/cloudwego-contrib/rgo/pkg/consts"
@@ -30,13 +29,13 @@ import (
 	"github.com/cloudwego/thriftgo/parser"
 )
 
-func (rg *RGOGenerator) GenerateRGOCode(serviceName, formatServiceName, idlPath, rgoSrcPath string) error {
+func (rg *RGOGenerator) GenerateRGOCode(idl config.IDL, idlPath, rgoSrcPath string) error {
 	exist, err := utils.FileExistsInPath(rgoSrcPath, consts.GoMod)
 	if err != nil {
 		return err
 	}
 
-	module := strings.ReplaceAll(rg.rgoConfig.ProjectModule, consts.RGOServiceName, formatServiceName)
+	module := rg.serviceModule(idl.FormatServiceName)
 
 	if !exist {
 		err = os.MkdirAll(rgoSrcPath, os.ModePerm)
@@ -54,12 +53,12 @@ func (rg *RGOGenerator) GenerateRGOCode(serviceName, formatServiceName, idlPath,
 
 	switch fileType {
 	case consts.ThriftPostfix:
-		err = rg.GenRgoBaseCode(module, serviceName, formatServiceName, idlPath, rgoSrcPath)
+		err = rg.GenRgoBaseCode(module, idl, idlPath, rgoSrcPath)
 		if err != nil {
 			return err
 		}
 
-		return rg.generatePackagesMeta(formatServiceName, rgoSrcPath)
+		return rg.generatePackagesMeta(idl, rgoSrcPath)
 	default:
 		return errors.New("unsupported idl file: " + fileType)
 	}
@@ -105,13 +104,5 @@ func (rg *RGOGenerator) GenRgoClientCode(serviceName, formatServiceName, idlPath
 }
 
 func (rg *RGOGenerator) buildClientTemplateData(serviceName, formatServiceName string, thriftFile *parser.Thrift) (*config.RGOClientTemplateData, error) {
-	data := &config.RGOClientTemplateData{
-		RGOModuleName:     rg.rgoConfig.ProjectModule,
-		ServiceName:       serviceName,
-		FormatServiceName: formatServiceName,
-		Imports:           []string{"context", "github.com/cloudwego/kitex/client", "github.com/cloudwego/kitex/client/callopt"},
-		Thrift:            thriftFile,
-	}
-
-	return data, nil
+	return plugin.NewClientTemplateData(rg.serviceModule(formatServiceName), serviceName, formatServiceName, thriftFile, nil)
 }
@@ -17,16 +17,20 @@
 package generator
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/cloudwego-contrib/rgo/pkg/config"
 	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/pkgmeta"
+	"github.com/cloudwego-contrib/rgo/pkg/rlog"
+	"github.com/cloudwego-contrib/rgo/pkg/utils"
 	"golang.org/x/tools/go/packages"
 )
 
-func (rg *RGOGenerator) generatePackagesMeta(formatServiceName, path string) error {
+func (rg *RGOGenerator) generatePackagesMeta(idl config.IDL, path string) error {
 	cfg := &packages.Config{
 		Mode: packages.NeedName |
 			packages.NeedFiles |
@@ -36,34 +40,87 @@ func (rg *RGOGenerator) generatePackagesMeta(formatServiceName, path string) err
 			packages.NeedTypesSizes |
 			packages.NeedModule |
 			packages.NeedEmbedFiles,
-		Dir: path,
+		Dir:   path,
+		Tests: true,
 	}
 
-	pkgs, err := packages.Load(cfg, filepath.Join(path, "..."))
+	roots, err := packages.Load(cfg, filepath.Join(path, "..."))
 	if err != nil {
 		return fmt.Errorf("failed to load packages: %v", err)
 	}
 
-	Packages := make([]*packages.Package, 0)
+	pkgs := testVariants(roots)
 
-	Packages = append(Packages, pkgs...)
-
-	data, err := json.Marshal(Packages)
+	commit, err := utils.GetLatestCommitID(filepath.Join(rg.RGOBasePath, consts.IDLPath, idl.RepoName))
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %v", err)
+		rlog.Warnf("failed to get the commit of %s: %v", idl.RepoName, err)
 	}
 
-	outputFile := filepath.Join(rg.RGOBasePath, consts.PkgMetaPath, formatServiceName, "rgo_packages.json")
+	metaPath := rg.packagesMetaPath(idl.FormatServiceName)
 
-	err = os.MkdirAll(filepath.Dir(outputFile), 0o755)
+	err = pkgmeta.Write(metaPath, pkgmeta.Header{Commit: commit, ConfigHash: rg.rgoConfig.IDLHash(idl)}, pkgs)
 	if err != nil {
-		return fmt.Errorf("failed to create directories: %v", err)
+		return fmt.Errorf("failed to write package metadata: %v", err)
 	}
 
-	err = os.WriteFile(outputFile, data, 0o644)
-	if err != nil {
-		return fmt.Errorf("failed to write JSON to file: %v", err)
+	err = os.Remove(filepath.Join(filepath.Dir(metaPath), consts.LegacyPkgMetaFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove legacy package metadata: %v", err)
 	}
 
 	return nil
 }
+
+// testVariants returns roots and the test variants of the packages of their
+// modules, which include the packages built again for the tests of the packages
+// they import, without the test mains. Test mains are generated in the build
+// cache, which may evict them, and gopls ignores them.
+func testVariants(roots []*packages.Package) []*packages.Package {
+	isTestMain := func(pkg *packages.Package) bool {
+		return pkg.Name == "main" && strings.HasSuffix(pkg.ID, ".test")
+	}
+
+	var pkgs []*packages.Package
+	modules := map[string]bool{}
+	isRoot := map[*packages.Package]bool{}
+	for _, pkg := range roots {
+		if !isTestMain(pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+		if pkg.Module != nil {
+			modules[pkg.Module.Path] = true
+		}
+		isRoot[pkg] = true
+	}
+
+	packages.Visit(roots, nil, func(pkg *packages.Package) {
+		if !isRoot[pkg] && pkgmeta.ForTest(pkg.ID) != "" && pkg.Module != nil && modules[pkg.Module.Path] {
+			pkgs = append(pkgs, pkg)
+		}
+	})
+
+	return pkgs
+}
+
+func (rg *RGOGenerator) packagesMetaPath(formatServiceName string) string {
+	return filepath.Join(rg.RGOBasePath, consts.PkgMetaPath, formatServiceName, consts.PkgMetaFile)
+}
+
+// packagesMetaStale reports why the package metadata of idl has to be
+// generated again, or "" if it's up to date.
+func (rg *RGOGenerator) packagesMetaStale(idl config.IDL) string {
+	header, err := pkgmeta.ReadHeader(rg.packagesMetaPath(idl.FormatServiceName))
+	if err != nil {
+		return err.Error()
+	}
+	if header.RGOVersion != consts.RGOVersion {
+		return fmt.Sprintf("it was generated by rgo %s, not %s", header.RGOVersion, consts.RGOVersion)
+	}
+	if toolsVersion := pkgmeta.ToolsVersion(); header.ToolsVersion != toolsVersion {
+		return fmt.Sprintf("it was generated with x/tools %s, not %s", header.ToolsVersion, toolsVersion)
+	}
+	if header.ConfigHash != rg.rgoConfig.IDLHash(idl) {
+		return "the configuration changed"
+	}
+	return ""
+}
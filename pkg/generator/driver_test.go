@@ -0,0 +1,122 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/pkgmeta"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestTestVariants(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":       "module rgo/echo\n\ngo 1.18\n",
+		"echo.go":      "package echo\n",
+		"echo_test.go": "package echo\n\nimport \"testing\"\n\nfunc TestEcho(t *testing.T) {}\n",
+		"x_test.go":    "package echo_test\n\nimport _ \"rgo/echo/sub\"\n",
+		"sub/sub.go":   "package sub\n\nimport _ \"rgo/echo\"\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	roots, err := packages.Load(&packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule,
+		Dir:   dir,
+		Env:   append(os.Environ(), "GOWORK=off", "GOFLAGS=", "GOPACKAGESDRIVER=off"),
+		Tests: true,
+	}, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, pkg := range testVariants(roots) {
+		ids = append(ids, pkg.ID)
+	}
+	sort.Strings(ids)
+
+	want := "rgo/echo, rgo/echo [rgo/echo.test], rgo/echo/sub, rgo/echo/sub [rgo/echo.test], rgo/echo_test [rgo/echo.test]"
+	if got := strings.Join(ids, ", "); got != want {
+		t.Errorf("got packages %s, want %s", got, want)
+	}
+}
+
+// writeMetaHeader writes a metadata file made of header only.
+func writeMetaHeader(t *testing.T, path string, header pkgmeta.Header) {
+	t.Helper()
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := binary.AppendUvarint([]byte("RGOMETA\n"), uint64(len(data)))
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path, append(file, data...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPackagesMetaStale(t *testing.T) {
+	idl := config.IDL{FormatServiceName: "echo", RepoName: "idl_repo"}
+	rg := &RGOGenerator{RGOBasePath: t.TempDir(), rgoConfig: &config.RGOConfig{ProjectModule: "example.com/app"}}
+	current := pkgmeta.Header{
+		FormatVersion: pkgmeta.FormatVersion,
+		RGOVersion:    consts.RGOVersion,
+		ToolsVersion:  pkgmeta.ToolsVersion(),
+		ConfigHash:    rg.rgoConfig.IDLHash(idl),
+	}
+
+	tests := []struct {
+		name   string
+		modify func(h *pkgmeta.Header)
+		stale  bool
+	}{
+		{name: "up to date", modify: func(h *pkgmeta.Header) {}},
+		{name: "rgo version", modify: func(h *pkgmeta.Header) { h.RGOVersion = "0.0.0" }, stale: true},
+		{name: "tools version", modify: func(h *pkgmeta.Header) { h.ToolsVersion = "v0.1.0" }, stale: true},
+		{name: "config", modify: func(h *pkgmeta.Header) { h.ConfigHash = "" }, stale: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := current
+			tt.modify(&header)
+			writeMetaHeader(t, rg.packagesMetaPath(idl.FormatServiceName), header)
+
+			if reason := rg.packagesMetaStale(idl); (reason != "") != tt.stale {
+				t.Errorf("packagesMetaStale = %q, want stale %v", reason, tt.stale)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/utils"
+)
+
+// ReconcileCache removes everything under rgoBasePath that no longer belongs
+// to an entry of c: cloned repos, generated modules, build outputs and
+// package metadata of removed repos and services.
+func ReconcileCache(rgoBasePath string, c *config.RGOConfig) error {
+	repos := make(map[string]bool, len(c.IDLRepos))
+	for _, repo := range c.IDLRepos {
+		repos[repo.RepoName] = true
+	}
+
+	services := make(map[string]bool, len(c.IDLs))
+	repoServices := make(map[string]map[string]bool, len(c.IDLRepos))
+	for _, idl := range c.IDLs {
+		services[idl.FormatServiceName] = true
+
+		if repoServices[idl.RepoName] == nil {
+			repoServices[idl.RepoName] = map[string]bool{}
+		}
+		repoServices[idl.RepoName][idl.FormatServiceName] = true
+	}
+
+	if err := removeUnknownEntries(filepath.Join(rgoBasePath, consts.IDLPath), repos); err != nil {
+		return err
+	}
+
+	if err := removeUnknownEntries(filepath.Join(rgoBasePath, consts.RepoPath), services); err != nil {
+		return err
+	}
+
+	if err := removeUnknownEntries(filepath.Join(rgoBasePath, consts.PkgMetaPath), services); err != nil {
+		return err
+	}
+
+	buildPath := filepath.Join(rgoBasePath, consts.BuildPath)
+
+	if err := removeUnknownEntries(buildPath, repos); err != nil {
+		return err
+	}
+
+	for repoName := range repos {
+		commits, err := readDirIfExist(filepath.Join(buildPath, repoName))
+		if err != nil {
+			return err
+		}
+
+		for _, commit := range commits {
+			if !commit.IsDir() {
+				continue
+			}
+
+			err = removeUnknownEntries(filepath.Join(buildPath, repoName, commit.Name()), repoServices[repoName])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return writeProjectMarker(rgoBasePath)
+}
+
+// PruneCache removes caches of orphaned projects, whose marked project
+// directory is gone, and build outputs of commits no longer referenced by c.
+// Caches of other projects are left alone, since they may still be in use.
+// Unreferenced build outputs are removed once they are older than maxAge,
+// then oldest first while the whole cache is larger than maxSize. A zero
+// maxAge or maxSize disables that limit.
+func PruneCache(rgoBasePath string, c *config.RGOConfig, maxAge time.Duration, maxSize int64) ([]string, error) {
+	var (
+		removed    []string
+		candidates []cacheEntry
+	)
+
+	currentCommits := make(map[string]string, len(c.IDLRepos))
+	for _, repo := range c.IDLRepos {
+		currentCommits[repo.RepoName] = repo.Commit
+	}
+
+	buildPath := filepath.Join(rgoBasePath, consts.BuildPath)
+
+	for repoName, currentCommit := range currentCommits {
+		commits, err := readDirIfExist(filepath.Join(buildPath, repoName))
+		if err != nil {
+			return removed, err
+		}
+
+		for _, commit := range commits {
+			if !commit.IsDir() || commit.Name() == currentCommit {
+				continue
+			}
+
+			entry, err := newCacheEntry(filepath.Join(buildPath, repoName, commit.Name()))
+			if err != nil {
+				return removed, err
+			}
+			candidates = append(candidates, entry)
+		}
+	}
+
+	cacheRoot := filepath.Dir(rgoBasePath)
+
+	projects, err := readDirIfExist(cacheRoot)
+	if err != nil {
+		return removed, err
+	}
+
+	for _, project := range projects {
+		projectPath := filepath.Join(cacheRoot, project.Name())
+		if !project.IsDir() || projectPath == filepath.Clean(rgoBasePath) {
+			continue
+		}
+
+		root, ok := readProjectMarker(projectPath)
+		if !ok {
+			continue
+		}
+
+		if _, err = os.Stat(root); !os.IsNotExist(err) {
+			continue
+		}
+
+		if err = os.RemoveAll(projectPath); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %v", projectPath, err)
+		}
+		removed = append(removed, projectPath)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].modTime.Before(candidates[j].modTime)
+	})
+
+	var kept []cacheEntry
+
+	for _, entry := range candidates {
+		if maxAge > 0 && time.Since(entry.modTime) > maxAge {
+			if err = os.RemoveAll(entry.path); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %v", entry.path, err)
+			}
+			removed = append(removed, entry.path)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	if maxSize <= 0 {
+		return removed, nil
+	}
+
+	total, err := utils.DirSize(cacheRoot)
+	if err != nil {
+		return removed, err
+	}
+
+	for _, entry := range kept {
+		if total <= maxSize {
+			break
+		}
+
+		if err = os.RemoveAll(entry.path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %v", entry.path, err)
+		}
+		removed = append(removed, entry.path)
+		total -= entry.size
+	}
+
+	return removed, nil
+}
+
+type cacheEntry struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// newCacheEntry uses the newest modification time among path and its direct
+// children, which is when the cache entry was last written to.
+func newCacheEntry(path string) (cacheEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+
+	entry := cacheEntry{path: path, modTime: info.ModTime()}
+
+	children, err := os.ReadDir(path)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+
+	for _, child := range children {
+		childInfo, err := child.Info()
+		if err != nil {
+			continue
+		}
+		if childInfo.ModTime().After(entry.modTime) {
+			entry.modTime = childInfo.ModTime()
+		}
+	}
+
+	entry.size, err = utils.DirSize(path)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// readProjectMarker returns the project directory recorded in the cache at
+// projectPath, and false when the cache has no marker.
+func readProjectMarker(projectPath string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(projectPath, consts.ProjectMarkerFile))
+	if err != nil {
+		return "", false
+	}
+
+	root := strings.TrimSpace(string(data))
+	return root, root != ""
+}
+
+func writeProjectMarker(rgoBasePath string) error {
+	root, err := utils.GetProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(rgoBasePath, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	return os.WriteFile(filepath.Join(rgoBasePath, consts.ProjectMarkerFile), []byte(root), 0o644)
+}
+
+func removeUnknownEntries(dir string, known map[string]bool) error {
+	entries, err := readDirIfExist(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if known[entry.Name()] {
+			continue
+		}
+
+		if err = os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove %s: %v", filepath.Join(dir, entry.Name()), err)
+		}
+	}
+
+	return nil
+}
+
+func readDirIfExist(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/utils"
+)
+
+var gcTestConfig = &config.RGOConfig{
+	IDLRepos: []config.IDLRepo{{RepoName: "idl_repo", Commit: "c1"}},
+	IDLs:     []config.IDL{{FormatServiceName: "svc", RepoName: "idl_repo"}},
+}
+
+// writeCacheFile creates the file at path, under dir, with size bytes.
+func writeCacheFile(t *testing.T, dir, path string, size int) {
+	t.Helper()
+
+	path = filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setCacheAge makes dir and its direct children last modified age ago.
+func setCacheAge(t *testing.T, dir string, age time.Duration) {
+	t.Helper()
+
+	mtime := time.Now().Add(-age)
+	children, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range children {
+		if err = os.Chtimes(filepath.Join(dir, child.Name()), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = os.Chtimes(dir, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconcileCache(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "project_hash")
+	for _, path := range []string{
+		"idl/idl_repo/echo.thrift",
+		"idl/removed_repo/echo.thrift",
+		"repo/svc/go.mod",
+		"repo/removed_svc/go.mod",
+		"pkg_meta/svc/rgo_packages.json",
+		"pkg_meta/removed_svc/rgo_packages.json",
+		"build/idl_repo/c1/svc/go.mod",
+		"build/idl_repo/c1/removed_svc/go.mod",
+		"build/idl_repo/c0/svc/go.mod",
+		"build/removed_repo/c1/svc/go.mod",
+	} {
+		writeCacheFile(t, base, path, 1)
+	}
+
+	if err := ReconcileCache(base, gcTestConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]bool{
+		"idl/idl_repo":                  true,
+		"idl/removed_repo":              false,
+		"repo/svc":                      true,
+		"repo/removed_svc":              false,
+		"pkg_meta/svc":                  true,
+		"pkg_meta/removed_svc":          false,
+		"build/idl_repo/c1/svc":         true,
+		"build/idl_repo/c1/removed_svc": false,
+		"build/idl_repo/c0/svc":         true,
+		"build/removed_repo":            false,
+	} {
+		_, err := os.Stat(filepath.Join(base, filepath.FromSlash(path)))
+		if exist := err == nil; exist != want {
+			t.Errorf("%s exists = %v, want %v", path, exist, want)
+		}
+	}
+
+	root, err := utils.GetProjectRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := readProjectMarker(base); !ok || got != root {
+		t.Errorf("readProjectMarker = %q, %v, want %q", got, ok, root)
+	}
+}
+
+func TestPruneCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxAge  time.Duration
+		maxSize int64
+		want    []string
+	}{
+		{
+			name: "no limits",
+			want: []string{"orphaned"},
+		},
+		{
+			name:   "max age",
+			maxAge: 24 * time.Hour,
+			want:   []string{"current/build/idl_repo/c0", "orphaned"},
+		},
+		{
+			name:    "max size",
+			maxSize: 2500,
+			want:    []string{"current/build/idl_repo/c0", "orphaned"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheRoot := t.TempDir()
+			liveRoot := t.TempDir()
+			base := filepath.Join(cacheRoot, "current")
+
+			writeCacheFile(t, base, "build/idl_repo/c1/svc/go.mod", 10)
+			writeCacheFile(t, base, "build/idl_repo/c0/svc/go.mod", 1000)
+			setCacheAge(t, filepath.Join(base, "build/idl_repo/c0"), 48*time.Hour)
+
+			// A project still on disk, unused for longer than the stale commit,
+			// which may be the other checkout of a user.
+			writeCacheFile(t, cacheRoot, "live/repo/svc/go.mod", 1000)
+			if err := os.WriteFile(filepath.Join(cacheRoot, "live", consts.ProjectMarkerFile), []byte(liveRoot), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			setCacheAge(t, filepath.Join(cacheRoot, "live"), 72*time.Hour)
+
+			// A project whose directory is gone.
+			writeCacheFile(t, cacheRoot, "orphaned/repo/svc/go.mod", 1000)
+			if err := os.WriteFile(filepath.Join(cacheRoot, "orphaned", consts.ProjectMarkerFile), []byte(filepath.Join(liveRoot, "removed")), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			// A project that was never reconciled, which may still be in use.
+			writeCacheFile(t, cacheRoot, "unmarked/repo/svc/go.mod", 1000)
+			setCacheAge(t, filepath.Join(cacheRoot, "unmarked"), 96*time.Hour)
+
+			removed, err := PruneCache(base, gcTestConfig, tt.maxAge, tt.maxSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, path := range removed {
+				rel, err := filepath.Rel(cacheRoot, path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("removed %v, want %v", got, tt.want)
+			}
+
+			for _, path := range removed {
+				if _, err = os.Stat(path); !os.IsNotExist(err) {
+					t.Errorf("%s still exists", path)
+				}
+			}
+			for _, path := range []string{"live", "unmarked", "current/build/idl_repo/c1"} {
+				if _, err = os.Stat(filepath.Join(cacheRoot, filepath.FromSlash(path))); err != nil {
+					t.Errorf("%s was removed: %v", path, err)
+				}
+			}
+		})
+	}
+}
@@ -41,6 +41,7 @@ type RGOGenerator struct {
 	RGOBasePath        string
 	rgoConfig          *config.RGOConfig
 	changedRepoCommit  *sync.Map
+	report             *GenerationReport
 	LspServer          *lsp.Server
 }
 
@@ -68,6 +69,7 @@ func NewRGOGenerator(lspServer *lsp.Server, rgoConfig *config.RGOConfig, rgoBase
 		RGOBasePath:        rgoBasePath,
 		rgoConfig:          rgoConfig,
 		changedRepoCommit:  &sync.Map{},
+		report:             NewGenerationReport(),
 		LspServer:          lspServer,
 	}
 }
@@ -99,6 +101,8 @@ func (rg *RGOGenerator) Run() {
 		return
 	}
 
+	RunHooks(rg.rgoConfig.Hooks.PreFetch, HookContext{Stage: consts.HookStagePreFetch}, rg.report)
+
 	rg.generateRepoCode()
 
 	err = rg.NotifyRGOProgressStop(consts.RGOProgressIDL)
@@ -115,12 +119,23 @@ func (rg *RGOGenerator) Run() {
 
 	rg.generateSrcCode()
 
+	if err = ReconcileCache(rg.RGOBasePath, rg.rgoConfig); err != nil {
+		rlog.Errorf("Failed to reconcile rgo cache: %v", err)
+	}
+
+	RunHooks(rg.rgoConfig.Hooks.PostRun, HookContext{Stage: consts.HookStagePostRun}, rg.report)
+
 	err = rg.NotifyRGOProgressStop(consts.RGOProgressSrc)
 	if err != nil {
 		rlog.Errorf("Failed to send notification stop progress: %v", err)
 		return
 	}
 
+	if err = rg.report.Err(); err != nil {
+		rlog.Errorf("RGO executed with errors: %v", err)
+		return
+	}
+
 	rlog.Info("RGO executed successfully")
 	err = rg.sendNotification(consts.MethodRGOWindowShowInfo, []byte(consts.RGOExecuteSuccessfully))
 	if err != nil {
@@ -211,9 +226,9 @@ func (rg *RGOGenerator) generateSrcCode() {
 	changedRepoCommit := rg.changedRepoCommit
 
 	if !rg.isGoPackagesDriver {
-		wd, err := os.Getwd()
+		wd, err := utils.GetProjectRoot()
 		if err != nil {
-			rlog.Errorf("Failed to get current working directory: %v", err)
+			rlog.Errorf("Failed to get project root: %v", err)
 			return
 		}
 
@@ -258,7 +273,11 @@ func (rg *RGOGenerator) generateSrcCode() {
 	idls := rg.rgoConfig.IDLs
 	for _, idl := range idls {
 		if _, ok := changedRepoCommit.Load(idl.RepoName); !ok {
-			continue
+			reason := rg.packagesMetaStale(idl)
+			if reason == "" {
+				continue
+			}
+			rlog.Infof("Regenerating %s: %s", idl.ServiceName, reason)
 		}
 		srcPath := filepath.Join(rg.RGOBasePath, consts.RepoPath, idl.FormatServiceName)
 
@@ -267,12 +286,20 @@ func (rg *RGOGenerator) generateSrcCode() {
 		idl := idl
 
 		eg.Go(func() error {
-			err := rg.GenerateRGOCode(idl.ServiceName, idl.FormatServiceName, idlPath, srcPath)
+			err := rg.GenerateRGOCode(idl, idlPath, srcPath)
+			rg.report.AddServiceResult(idl.ServiceName, srcPath, err)
 			if err != nil {
 				rlog.Errorf("Failed to generate rgo code for %s: %v", idl.ServiceName, err)
 				return err
 			}
 
+			RunHooks(rg.rgoConfig.Hooks.PostService, HookContext{
+				Stage:       consts.HookStagePostService,
+				ServiceName: idl.ServiceName,
+				ModulePath:  rg.serviceModule(idl.FormatServiceName),
+				OutputDir:   srcPath,
+			}, rg.report)
+
 			if !rg.isGoPackagesDriver {
 				rlog.Info(srcPath)
 				err = utils.AddModuleToGoWork(srcPath)
@@ -299,6 +326,10 @@ func (rg *RGOGenerator) generateSrcCode() {
 	}
 }
 
+func (rg *RGOGenerator) serviceModule(formatServiceName string) string {
+	return strings.ReplaceAll(rg.rgoConfig.ProjectModule, consts.RGOServiceName, formatServiceName)
+}
+
 func (rg *RGOGenerator) cloneRemoteRepo(repo config.IDLRepo, path, commit string) (string, error) {
 	var id string
 	var err error
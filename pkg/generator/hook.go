@@ -0,0 +1,104 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+)
+
+// HookContext describes the point of the run a hook is invoked at. Service
+// fields are only set for post_service hooks.
+type HookContext struct {
+	Stage       string
+	ServiceName string
+	ModulePath  string
+	OutputDir   string
+}
+
+// HookFunc is a hook implemented in Go and referenced from config by name.
+type HookFunc func(hc HookContext) error
+
+var (
+	hookPluginsMu sync.RWMutex
+	hookPlugins   = map[string]HookFunc{}
+)
+
+// RegisterHookPlugin makes fn available to the `plugin` field of a hook.
+func RegisterHookPlugin(name string, fn HookFunc) {
+	hookPluginsMu.Lock()
+	defer hookPluginsMu.Unlock()
+
+	hookPlugins[name] = fn
+}
+
+func getHookPlugin(name string) (HookFunc, bool) {
+	hookPluginsMu.RLock()
+	defer hookPluginsMu.RUnlock()
+
+	fn, ok := hookPlugins[name]
+	return fn, ok
+}
+
+// RunHooks runs hooks in order and records every failure in report. It keeps
+// going after a failure so that one broken hook doesn't hide the others.
+func RunHooks(hooks []config.Hook, hc HookContext, report *GenerationReport) {
+	for _, hook := range hooks {
+		if err := runHook(hook, hc); err != nil {
+			report.AddHookFailure(hc, hook, err)
+		}
+	}
+}
+
+func runHook(hook config.Hook, hc HookContext) error {
+	switch {
+	case hook.Plugin != "":
+		fn, ok := getHookPlugin(hook.Plugin)
+		if !ok {
+			return fmt.Errorf("hook plugin %s is not registered", hook.Plugin)
+		}
+		return fn(hc)
+	case hook.Command != "":
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", hook.Command)
+		} else {
+			cmd = exec.Command("sh", "-c", hook.Command)
+		}
+
+		cmd.Env = append(os.Environ(),
+			consts.HookStageEnv+"="+hc.Stage,
+			consts.HookServiceNameEnv+"="+hc.ServiceName,
+			consts.HookModulePathEnv+"="+hc.ModulePath,
+			consts.HookOutputDirEnv+"="+hc.OutputDir,
+		)
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to execute '%s': %v, output: %s", hook.Command, err, string(output))
+		}
+		return nil
+	default:
+		return fmt.Errorf("hook has neither command nor plugin")
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+)
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands run by sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "env")
+	t.Setenv("RGO_TEST_HOOK_OUT", out)
+
+	var pluginCalls []HookContext
+	RegisterHookPlugin("test_record", func(hc HookContext) error {
+		pluginCalls = append(pluginCalls, hc)
+		return nil
+	})
+	RegisterHookPlugin("test_fail", func(HookContext) error {
+		return errors.New("plugin failed")
+	})
+
+	hc := HookContext{
+		Stage:       consts.HookStagePostService,
+		ServiceName: "echo.service",
+		ModulePath:  "rgo/echo_service",
+		OutputDir:   "/tmp/rgo/echo_service",
+	}
+	report := NewGenerationReport()
+
+	RunHooks([]config.Hook{
+		{Name: "env", Command: `printf '%s\n' "$` + consts.HookStageEnv + `" "$` + consts.HookServiceNameEnv + `" "$` + consts.HookModulePathEnv + `" "$` + consts.HookOutputDirEnv + `" > "$RGO_TEST_HOOK_OUT"`},
+		{Name: "fail", Command: "echo broken; exit 3"},
+		{Plugin: "test_record"},
+		{Plugin: "test_fail"},
+		{Plugin: "test_missing"},
+		{Name: "empty"},
+	}, hc, report)
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "post_service\necho.service\nrgo/echo_service\n/tmp/rgo/echo_service\n"; got != want {
+		t.Errorf("hook environment = %q, want %q", got, want)
+	}
+
+	if len(pluginCalls) != 1 || pluginCalls[0] != hc {
+		t.Errorf("plugin calls = %+v, want [%+v]", pluginCalls, hc)
+	}
+
+	var hooks []string
+	for _, f := range report.HookFailures {
+		if f.Stage != hc.Stage || f.ServiceName != hc.ServiceName {
+			t.Errorf("failure of %s recorded for %s/%s", f.Hook, f.Stage, f.ServiceName)
+		}
+		hooks = append(hooks, f.Hook)
+	}
+	if got, want := strings.Join(hooks, ","), "fail,test_fail,test_missing,empty"; got != want {
+		t.Fatalf("failed hooks = %s, want %s", got, want)
+	}
+
+	if msg := report.HookFailures[0].Err.Error(); !strings.Contains(msg, "exit status 3") || !strings.Contains(msg, "broken") {
+		t.Errorf("command failure = %q, want the exit status and output", msg)
+	}
+	if msg := report.HookFailures[2].Err.Error(); msg != "hook plugin test_missing is not registered" {
+		t.Errorf("missing plugin failure = %q", msg)
+	}
+}
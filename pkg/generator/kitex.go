@@ -20,12 +20,13 @@ import (
 	"fmt"
 	"os/exec"
 
+	"github.com/cloudwego-contrib/rgo/pkg/config"
 	"github.com/cloudwego-contrib/rgo/pkg/consts"
 
 	"github.com/cloudwego/thriftgo/parser"
 )
 
-func (rg *RGOGenerator) GenRgoBaseCode(module, serviceName, formatServiceName, idlPath, rgoSrcPath string) error {
+func (rg *RGOGenerator) GenRgoBaseCode(module string, idl config.IDL, idlPath, rgoSrcPath string) error {
 	customArgs := []string{
 		"-frugal-pretouch",
 		"-thrift", "template=slim",
@@ -42,11 +43,13 @@ func (rg *RGOGenerator) GenRgoBaseCode(module, serviceName, formatServiceName, i
 		fmt.Sprintf("--%s", consts.PluginTypeFlag), consts.EditPeriod,
 		fmt.Sprintf("--%s", consts.PwdFlag), rgoSrcPath,
 		fmt.Sprintf("--%s", consts.ModuleFlag), module,
-		fmt.Sprintf("--%s", consts.ServiceNameFlag), serviceName,
-		fmt.Sprintf("--%s", consts.FormatServiceNameFlag), formatServiceName,
+		fmt.Sprintf("--%s", consts.ServiceNameFlag), idl.ServiceName,
+		fmt.Sprintf("--%s", consts.FormatServiceNameFlag), idl.FormatServiceName,
 		fmt.Sprintf("--%s", consts.IDLPathFlag), idlPath,
 	}
 
+	args = append(args, IDLPluginArgs(idl)...)
+
 	for _, customArg := range customArgs {
 		args = append(args, fmt.Sprintf("--%s", consts.KitexArgsFlag), customArg)
 	}
@@ -60,6 +63,52 @@ func (rg *RGOGenerator) GenRgoBaseCode(module, serviceName, formatServiceName, i
 	return nil
 }
 
+// IDLPluginArgs returns the rgo kitex flags passing the options of idl on to
+// the rgo plugin.
+func IDLPluginArgs(idl config.IDL) []string {
+	var args []string
+
+	if idl.Mode != "" {
+		args = append(args, fmt.Sprintf("--%s", consts.IDLModeFlag), idl.Mode)
+	}
+
+	if idl.Protocol != "" {
+		args = append(args, fmt.Sprintf("--%s", consts.ProtocolFlag), idl.Protocol)
+	}
+
+	if idl.Mock {
+		args = append(args, fmt.Sprintf("--%s", consts.MockFlag))
+	}
+
+	if idl.Server {
+		args = append(args, fmt.Sprintf("--%s", consts.ServerFlag))
+	}
+
+	for _, hostPort := range idl.Client.HostPorts {
+		args = append(args, fmt.Sprintf("--%s", consts.ClientHostPortsFlag), hostPort)
+	}
+
+	clientFlags := []struct {
+		flag, value string
+	}{
+		{consts.ClientRPCTimeoutFlag, idl.Client.RPCTimeout},
+		{consts.ClientConnectTimeoutFlag, idl.Client.ConnectTimeout},
+		{consts.ClientTransportFlag, idl.Client.Transport},
+		{consts.ClientResolverFlag, idl.Client.Resolver},
+	}
+	for _, f := range clientFlags {
+		if f.value != "" {
+			args = append(args, fmt.Sprintf("--%s", f.flag), f.value)
+		}
+	}
+
+	for _, m := range idl.MiddlewareChain {
+		args = append(args, fmt.Sprintf("--%s", consts.MiddlewaresFlag), m.Ref())
+	}
+
+	return args
+}
+
 func parseIDLFile(idlFile string) (*parser.Thrift, error) {
 	thriftFile, err := parser.ParseFile(idlFile, nil, true)
 	if err != nil {
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// LoadAPI type-checks the generated client package at the root of the module
+// in dir and returns its exported API, as described by DescribeAPI.
+func LoadAPI(dir string) ([]string, error) {
+	cfg := &packages.Config{
+		// Dependencies are type-checked from source rather than from export
+		// data, whose format depends on the version of the go command.
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+		// The generated module must be loaded by the go command, not by the
+		// rgo packages driver the IDE may have set.
+		Env: append(os.Environ(), "GOPACKAGESDRIVER=off"),
+	}
+
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package in %s: %v", dir, err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return nil, fmt.Errorf("failed to type-check package in %s: %v", dir, pkgs[0].Errors[0])
+	}
+
+	return DescribeAPI(pkgs[0].Types), nil
+}
+
+// DescribeAPI returns one line per exported declaration of pkg, exported
+// field of its struct types and method of their method sets, sorted. Packages
+// of the module of pkg are named relative to it, so that the same client
+// generated in two modules has the same description.
+func DescribeAPI(pkg *types.Package) []string {
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		if strings.HasPrefix(p.Path(), pkg.Path()+"/") {
+			return "." + strings.TrimPrefix(p.Path(), pkg.Path())
+		}
+		return p.Path()
+	}
+
+	var api []string
+	scope := pkg.Scope()
+
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+
+		tn, ok := obj.(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			api = append(api, types.ObjectString(obj, qualifier))
+			continue
+		}
+
+		typ := tn.Type()
+		switch u := typ.Underlying().(type) {
+		case *types.Struct:
+			api = append(api, "type "+name+" struct")
+			for i := 0; i < u.NumFields(); i++ {
+				f := u.Field(i)
+				if !f.Exported() {
+					continue
+				}
+				line := "field " + name + "." + f.Name() + " " + types.TypeString(f.Type(), qualifier)
+				if f.Embedded() {
+					line += " (embedded)"
+				}
+				api = append(api, line)
+			}
+		case *types.Interface:
+			api = append(api, "type "+name+" interface")
+		default:
+			api = append(api, "type "+name+" "+types.TypeString(u, qualifier))
+		}
+
+		// The method set of *T includes the one of T, as well as the
+		// methods promoted from embedded fields.
+		if _, ok := typ.Underlying().(*types.Interface); !ok {
+			typ = types.NewPointer(typ)
+		}
+		methods := types.NewMethodSet(typ)
+		for i := 0; i < methods.Len(); i++ {
+			m := methods.At(i).Obj()
+			if !m.Exported() {
+				continue
+			}
+			sig := types.TypeString(m.Type(), qualifier)
+			api = append(api, "method "+name+"."+m.Name()+strings.TrimPrefix(sig, "func"))
+		}
+	}
+
+	sort.Strings(api)
+	return api
+}
+
+// DiffAPI returns the lines of edit missing from build, prefixed with "-",
+// and those of build missing from edit, prefixed with "+".
+func DiffAPI(edit, build []string) []string {
+	inEdit := make(map[string]bool, len(edit))
+	for _, line := range edit {
+		inEdit[line] = true
+	}
+	inBuild := make(map[string]bool, len(build))
+	for _, line := range build {
+		inBuild[line] = true
+	}
+
+	var diff []string
+	for _, line := range edit {
+		if !inBuild[line] {
+			diff = append(diff, "- "+line)
+		}
+	}
+	for _, line := range build {
+		if !inEdit[line] {
+			diff = append(diff, "+ "+line)
+		}
+	}
+
+	return diff
+}
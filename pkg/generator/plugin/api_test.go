@@ -0,0 +1,182 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego/kitex/tool/cmd/kitex/sdk"
+	"github.com/cloudwego/thriftgo/parser"
+	thriftgoplugin "github.com/cloudwego/thriftgo/plugin"
+)
+
+// TestEditBuildAPIParity generates the edit-period and build-period clients of
+// the IDLs under testdata and checks that their exported APIs are the same, so
+// that code type-checked by the IDE against the former compiles against the
+// latter. It is skipped when the Kitex dependencies can't be fetched.
+func TestEditBuildAPIParity(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping generation test in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+
+	idls, err := filepath.Glob(filepath.Join("testdata", "*.thrift"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	idlDir := filepath.Join(dir, "idl")
+	if err = os.MkdirAll(idlDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for _, idl := range idls {
+		content, err := os.ReadFile(idl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(idlDir, filepath.Base(idl)), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	goSum, err := os.ReadFile(filepath.Join("..", "..", "..", "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, idl := range idls {
+		name := filepath.Base(idl)
+		ast, err := parser.ParseFile(idl, nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ast.Services) == 0 {
+			continue
+		}
+
+		formatServiceName := strings.TrimSuffix(name, ".thrift") + "_client"
+		module := "rgo/" + formatServiceName
+
+		apis := map[string][]string{}
+		for _, period := range []string{consts.EditPeriod, consts.BuildPeriod} {
+			genDir := filepath.Join(dir, period, formatServiceName)
+			if err = os.MkdirAll(genDir, os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			goMod := "module " + module + "\n\ngo 1.18\n\nrequire github.com/cloudwego/kitex v0.10.3\n"
+			if err = os.WriteFile(filepath.Join(genDir, consts.GoMod), []byte(goMod), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err = os.WriteFile(filepath.Join(genDir, "go.sum"), goSum, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Middlewares are left out: their packages don't exist.
+			rgoPlugin, err := GetRGOPlugin(period, genDir, module, "test.service", formatServiceName, true, serverIDLs[name], testClients[name], nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			idlPath := filepath.Join(idlDir, name)
+			switch {
+			case genericIDLs[name]:
+				err = GenerateGenericClient(rgoPlugin, idlPath)
+			case httpIDLs[name]:
+				err = GenerateHTTPClient(rgoPlugin, idlPath, nil)
+			default:
+				err = sdk.RunKitexTool(genDir, []thriftgoplugin.SDKPlugin{rgoPlugin}, "--module", module, idlPath)
+			}
+			if err != nil {
+				t.Skipf("failed to generate code, the kitex dependencies are probably unavailable: %v", err)
+			}
+
+			apis[period], err = LoadAPI(genDir)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+
+		if diff := DiffAPI(apis[consts.EditPeriod], apis[consts.BuildPeriod]); len(diff) > 0 {
+			t.Errorf("%s: the edit-period (-) and build-period (+) APIs differ:\n%s", name, strings.Join(diff, "\n"))
+		}
+	}
+}
+
+func TestDiffAPI(t *testing.T) {
+	edit := []string{"func Ping(ctx context.Context) error", "type Client struct"}
+	build := []string{"func Ping(ctx context.Context) (string, error)", "type Client struct"}
+
+	diff := DiffAPI(edit, build)
+	want := []string{"- func Ping(ctx context.Context) error", "+ func Ping(ctx context.Context) (string, error)"}
+	if strings.Join(diff, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", diff, want)
+	}
+
+	if diff = DiffAPI(edit, edit); len(diff) != 0 {
+		t.Errorf("got %q for identical APIs", diff)
+	}
+}
+
+// TestDescribeAPI checks that the same client generated in two modules is
+// described the same way.
+func TestDescribeAPI(t *testing.T) {
+	var apis [][]string
+	for _, module := range []string{"rgo/a", "rgo/b"} {
+		dir := t.TempDir()
+		files := map[string]string{
+			consts.GoMod:          "module " + module + "\n\ngo 1.18\n",
+			"client.go":           "package client\n\nimport \"" + module + "/kitex_gen/echo\"\n\ntype Client struct {\n\techo.Echo\n\tName string\n\tid   int\n}\n\nfunc (c *Client) Call(req *echo.Req) error { return nil }\n\nfunc New() Client { return Client{} }\n",
+			"kitex_gen/echo/e.go": "package echo\n\ntype Req struct{}\n\ntype Echo interface {\n\tSend(req *Req) error\n}\n",
+		}
+		for name, content := range files {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		api, err := LoadAPI(dir)
+		if err != nil {
+			t.Skipf("failed to load package: %v", err)
+		}
+		apis = append(apis, api)
+	}
+
+	want := []string{
+		"field Client.Echo ./kitex_gen/echo.Echo (embedded)",
+		"field Client.Name string",
+		"func New() Client",
+		"method Client.Call(req *./kitex_gen/echo.Req) error",
+		"method Client.Send(req *./kitex_gen/echo.Req) error",
+		"type Client struct",
+	}
+	for _, api := range apis {
+		if strings.Join(api, "\n") != strings.Join(want, "\n") {
+			t.Errorf("got:\n%s\nwant:\n%s", strings.Join(api, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
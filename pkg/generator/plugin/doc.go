@@ -0,0 +1,216 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// commentText turns the reserved comments of a thriftgo AST node (//, # and
+// /* */ comments, one per line) into the lines of their text.
+func commentText(comments string) []string {
+	var lines []string
+	inBlock := false
+
+	for _, line := range strings.Split(comments, "\n") {
+		line = strings.TrimSpace(line)
+
+		if !inBlock {
+			switch {
+			case strings.HasPrefix(line, "//"):
+				lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+				continue
+			case strings.HasPrefix(line, "/*"):
+				inBlock = true
+				line = line[2:]
+			default:
+				continue
+			}
+		}
+
+		if i := strings.Index(line, "*/"); i >= 0 {
+			inBlock = false
+			line = line[:i]
+		}
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	}
+
+	// Drop the blank lines left by /** and */ lines.
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// idlSource locates definitions in the source of an IDL file, which the
+// thriftgo AST has no positions for.
+type idlSource struct {
+	name string
+	// code is the source with comments blanked out, so that names mentioned
+	// in comments aren't taken for definitions.
+	code string
+}
+
+// newIDLSource reads the IDL at filename. It returns nil if the file can't be
+// read, in which case no line is found.
+func newIDLSource(filename string) *idlSource {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+
+	return &idlSource{name: filepath.Base(filename), code: blankComments(string(content))}
+}
+
+// serviceLine returns the line defining service name, or 0 if it isn't found.
+func (s *idlSource) serviceLine(name string) int {
+	if s == nil {
+		return 0
+	}
+	return s.line(s.find(`\bservice\s+`+regexp.QuoteMeta(name)+`\b`, 0))
+}
+
+// functionLines returns the lines defining the functions of service name, in
+// IDL order. Functions that aren't found are at line 0.
+func (s *idlSource) functionLines(service string, functions []string) []int {
+	lines := make([]int, len(functions))
+	if s == nil {
+		return lines
+	}
+
+	offset := s.find(`\bservice\s+`+regexp.QuoteMeta(service)+`\b`, 0)
+	if offset < 0 {
+		return lines
+	}
+	for i, f := range functions {
+		// Functions are defined in order, so each is looked up after the previous one.
+		pos := s.find(`\b`+regexp.QuoteMeta(f)+`\s*\(`, offset)
+		if pos < 0 {
+			continue
+		}
+		lines[i] = s.line(pos)
+		offset = pos + len(f)
+	}
+
+	return lines
+}
+
+func (s *idlSource) find(pattern string, offset int) int {
+	loc := regexp.MustCompile(pattern).FindStringIndex(s.code[offset:])
+	if loc == nil {
+		return -1
+	}
+	return offset + loc[0]
+}
+
+func (s *idlSource) line(pos int) int {
+	if pos < 0 {
+		return 0
+	}
+	return strings.Count(s.code[:pos], "\n") + 1
+}
+
+// ref returns the reference to line shown in doc comments.
+func (s *idlSource) ref(line int) string {
+	if line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("IDL: %s:%d", s.name, line)
+}
+
+// blankComments replaces the comments of an IDL source with spaces, keeping
+// line breaks so that lines don't move.
+func blankComments(src string) string {
+	b := []byte(src)
+
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '"' || b[i] == '\'':
+			quote := b[i]
+			for i++; i < len(b) && b[i] != quote && b[i] != '\n'; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+			}
+		case b[i] == '#' || (b[i] == '/' && i+1 < len(b) && b[i+1] == '/'):
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+			end := strings.Index(string(b[i+2:]), "*/")
+			if end < 0 {
+				end = len(b)
+			} else {
+				end += i + 4
+			}
+			for ; i < end; i++ {
+				if b[i] != '\n' {
+					b[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+
+	return string(b)
+}
+
+// methodDoc builds the doc comment of a method from the comments of the
+// function and its arguments, followed by the reference to its definition.
+func methodDoc(comments string, args []argDoc, ref string) []string {
+	doc := commentText(comments)
+	paragraph := len(doc) > 0
+
+	// Arguments are documented together, in a paragraph of their own.
+	for _, a := range args {
+		text := commentText(a.comments)
+		if len(text) == 0 {
+			continue
+		}
+		if paragraph {
+			doc = append(doc, "")
+			paragraph = false
+		}
+		doc = append(doc, a.name+": "+text[0])
+		doc = append(doc, text[1:]...)
+	}
+
+	return withRef(doc, ref)
+}
+
+type argDoc struct {
+	name     string
+	comments string
+}
+
+func withRef(doc []string, ref string) []string {
+	if ref == "" {
+		return doc
+	}
+	if len(doc) > 0 {
+		doc = append(doc, "")
+	}
+	return append(doc, ref)
+}
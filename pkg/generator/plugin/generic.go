@@ -0,0 +1,89 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/plugin"
+)
+
+// GenerateGenericClient generates a client calling the service of the IDL at
+// idlPath with Kitex's JSON generic call. Unlike the Kitex flow, no kitex_gen
+// is generated: the IDL is loaded from idlPath at runtime.
+func GenerateGenericClient(r *RGOPlugin, idlPath string) error {
+	idlPath, err := filepath.Abs(idlPath)
+	if err != nil {
+		return err
+	}
+
+	ast, err := parser.ParseFile(idlPath, nil, true)
+	if err != nil {
+		return fmt.Errorf("failed to parse idl %s: %v", idlPath, err)
+	}
+
+	// kitex_gen left by a previous generation in the Kitex mode is stale
+	err = os.RemoveAll(filepath.Join(r.Pwd, "kitex_gen"))
+	if err != nil {
+		return fmt.Errorf("failed to remove kitex_gen: %v", err)
+	}
+
+	r.Generic = true
+	r.IDLPath = idlPath
+
+	res := r.Invoke(&plugin.Request{AST: ast})
+	if res == nil {
+		return fmt.Errorf("unknown rgo plugin type: %s", r.Type)
+	}
+	if res.Error != nil {
+		return errors.New(*res.Error)
+	}
+
+	return nil
+}
+
+// useGenericCall turns the methods of data into JSON in, JSON out methods, the
+// way Kitex's JSON generic call exchanges requests and responses, and checks
+// that the service can be called that way.
+func useGenericCall(data *config.RGOClientTemplateData, idlPath string) error {
+	for _, m := range data.Service.Methods {
+		if m.Streaming != "" {
+			return fmt.Errorf("generic call doesn't support streaming method %s", m.RawName)
+		}
+		if len(m.Args) != 1 {
+			return fmt.Errorf("generic call requires method %s to take exactly one argument", m.RawName)
+		}
+
+		m.Args[0].Type = "string"
+		if !m.Void {
+			m.Resp = "string"
+		}
+	}
+
+	// Exceptions come back as plain errors, and no kitex_gen type is referenced.
+	data.Service.Exceptions = nil
+	data.PkgImports = nil
+	data.Generic = true
+	data.IDLPath = idlPath
+
+	return nil
+}
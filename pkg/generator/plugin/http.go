@@ -0,0 +1,332 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/utils"
+	"github.com/cloudwego/kitex/tool/cmd/kitex/sdk"
+	"github.com/cloudwego/thriftgo/generator/golang"
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/plugin"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+// GenerateHTTPClient generates kitex_gen, for the request and response types,
+// and a client calling the HTTP routes of the service of the IDL at idlPath.
+func GenerateHTTPClient(r *RGOPlugin, idlPath string, kitexArgs []string) error {
+	r.HTTP = true
+	r.skipTidy = true
+
+	err := sdk.RunKitexTool(r.Pwd, []plugin.SDKPlugin{r}, append(kitexArgs, "--module", r.ProjectModule, idlPath)...)
+	if err != nil {
+		return err
+	}
+
+	// Tidying in the rgo plugin, before Kitex writes the part of kitex_gen
+	// importing it, would drop Kitex: the HTTP client doesn't import it.
+	return utils.RunGoModTidyInDir(r.Pwd)
+}
+
+// httpMethods are the api annotations declaring the route of a function, the
+// way Hertz reads them, and the HTTP methods of their routes.
+var httpMethods = []struct {
+	annotation string
+	method     string
+}{
+	{"api.get", "GET"},
+	{"api.post", "POST"},
+	{"api.put", "PUT"},
+	{"api.patch", "PATCH"},
+	{"api.delete", "DELETE"},
+	{"api.head", "HEAD"},
+	{"api.options", "OPTIONS"},
+}
+
+// unsupportedHTTPAnnotations are the api annotations of request fields which
+// the HTTP client can't send: requests are sent as JSON.
+var unsupportedHTTPAnnotations = []string{"api.form", "api.cookie", "api.raw_body"}
+
+// httpLocalNames are the names used in the bodies of the HTTP client methods
+// on top of localNames, including the packages they call.
+var httpLocalNames = []string{"query", "header", "v", "url", "http"}
+
+// useHTTP turns the methods of data into calls of the routes declared by the
+// api annotations of their functions, with the path, query and header
+// parameters taken from the annotations of the request fields.
+func useHTTP(data *config.RGOClientTemplateData, ast *parser.Thrift, generatorParameters []string, packagePrefix string) error {
+	scope, err := buildScope(ast, generatorParameters, packagePrefix)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range scope.Services()[0].Functions() {
+		m := data.Service.Methods[i]
+		if m.Streaming != "" {
+			return fmt.Errorf("http client doesn't support streaming method %s", m.RawName)
+		}
+
+		local := names{}
+		local.reserve(localNames...)
+		local.reserve(httpLocalNames...)
+		for _, a := range m.Args {
+			a.Name = local.add(a.Name)
+		}
+
+		m.Route, err = resolveRoute(scope, f, m)
+		if err != nil {
+			return fmt.Errorf("invalid route of method %s: %v", m.RawName, err)
+		}
+	}
+
+	// Exceptions come back as status errors, only the signatures reference kitex_gen.
+	var pkgImports []config.RGOImport
+	for _, imp := range data.PkgImports {
+		if imp.InSignatures {
+			imp.InExceptions = false
+			pkgImports = append(pkgImports, imp)
+		}
+	}
+
+	data.Service.Exceptions = nil
+	data.PkgImports = pkgImports
+	data.Imports = []string{"context", "net/http"}
+	data.HTTP = true
+
+	return nil
+}
+
+func resolveRoute(scope *golang.Scope, f *golang.Function, m *config.RGOMethod) (*config.RGOHTTPRoute, error) {
+	route := &config.RGOHTTPRoute{}
+	var path string
+
+	for _, hm := range httpMethods {
+		values := f.Annotations.Get(hm.annotation)
+		if len(values) == 0 {
+			continue
+		}
+		if route.Method != "" || len(values) > 1 {
+			return nil, fmt.Errorf("more than one api route annotation")
+		}
+		route.Method, path = hm.method, values[0]
+	}
+	if route.Method == "" {
+		return nil, fmt.Errorf("no api route annotation, e.g. api.get=\"/path\"")
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("route %q doesn't start with /", path)
+	}
+
+	pathParams := map[string]*config.RGOHTTPParam{}
+
+	switch len(m.Args) {
+	case 0:
+	case 1:
+		route.Body = route.Method == "POST" || route.Method == "PUT" || route.Method == "PATCH"
+
+		ast, st, err := requestStruct(scope, f.Arguments()[0].Type)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fd := range st.Fields() {
+			for _, key := range unsupportedHTTPAnnotations {
+				if len(fd.Annotations.Get(key)) > 0 {
+					return nil, fmt.Errorf("field %s: %s isn't supported, requests are sent as JSON", fd.Name, key)
+				}
+			}
+
+			expr := m.Args[0].Name + "." + fd.GoName().String()
+
+			for _, binding := range []struct {
+				key    string
+				params *[]*config.RGOHTTPParam
+			}{
+				{"api.path", nil},
+				{"api.query", &route.Query},
+				{"api.header", &route.Headers},
+			} {
+				values := fd.Annotations.Get(binding.key)
+				if len(values) == 0 {
+					continue
+				}
+
+				// Hertz annotations may carry options after the name (e.g., api.query="page,required").
+				name := strings.TrimSpace(strings.Split(values[0], ",")[0])
+				if name == "" {
+					name = fd.Name
+				}
+
+				p, err := httpParam(ast, fd, name, expr)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %v", fd.Name, err)
+				}
+
+				if binding.params != nil {
+					*binding.params = append(*binding.params, p)
+					continue
+				}
+				if p.Optional || p.List {
+					return nil, fmt.Errorf("field %s: path parameter %s must be a required scalar field", fd.Name, name)
+				}
+				pathParams[name] = p
+			}
+		}
+	default:
+		return nil, fmt.Errorf("http routes take at most one request argument")
+	}
+
+	var err error
+	route.Path, err = pathExpr(path, pathParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return route, nil
+}
+
+// requestStruct returns the struct of the request argument type t, and the
+// AST defining it.
+func requestStruct(scope *golang.Scope, t *parser.Type) (*parser.Thrift, *golang.StructLike, error) {
+	ast, t, err := semantic.Deref(scope.AST(), t)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !t.Category.IsStruct() {
+		return nil, nil, fmt.Errorf("the request argument must be a struct")
+	}
+
+	for _, inc := range scope.Includes() {
+		if inc.Scope.AST() == ast {
+			if st := inc.Scope.StructLike(t.Name); st != nil {
+				return ast, st, nil
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("struct %s not found", t.Name)
+}
+
+// httpParam builds the parameter sending field fd of a request, whose Go
+// expression is expr, as name.
+func httpParam(ast *parser.Thrift, fd *golang.Field, name, expr string) (*config.RGOHTTPParam, error) {
+	p := &config.RGOHTTPParam{
+		Name:     name,
+		Field:    expr,
+		Optional: strings.HasPrefix(fd.GoTypeName().String(), "*"),
+	}
+
+	ast, t, err := semantic.Deref(ast, fd.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	value := expr
+	if p.Optional {
+		value = "*" + expr
+	}
+	if t.Name == "list" || t.Name == "set" {
+		p.List = true
+		value = "v"
+		if _, t, err = semantic.Deref(ast, t.ValueType); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case t.Name == "binary":
+		p.Value = "string(" + value + ")"
+	case t.Category.IsEnum():
+		// Hertz binds enums from their numbers.
+		p.Value = "fmt.Sprint(int64(" + value + "))"
+	case t.Category.IsBaseType() || isBaseTypeName(t.Name):
+		p.Value = "fmt.Sprint(" + value + ")"
+	default:
+		return nil, fmt.Errorf("%s can't be sent as %s", t.Name, name)
+	}
+
+	return p, nil
+}
+
+func isBaseTypeName(name string) bool {
+	switch name {
+	case "bool", "byte", "i8", "i16", "i32", "i64", "double", "string":
+		return true
+	}
+	return false
+}
+
+// pathExpr returns the Go expression of the path of route, with its :name,
+// {name} and *name segments replaced by the values of params.
+func pathExpr(route string, params map[string]*config.RGOHTTPParam) (string, error) {
+	var (
+		parts   []string
+		literal string
+		used    = map[string]bool{}
+	)
+
+	for i, segment := range strings.Split(route, "/") {
+		if i > 0 {
+			literal += "/"
+		}
+
+		name, wildcard := "", false
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = segment[1:]
+		case strings.HasPrefix(segment, "*"):
+			name, wildcard = segment[1:], true
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			name = segment[1 : len(segment)-1]
+		default:
+			literal += segment
+			continue
+		}
+
+		p, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("route parameter %s has no api.path field", name)
+		}
+		used[name] = true
+
+		if literal != "" {
+			parts = append(parts, strconv.Quote(literal))
+			literal = ""
+		}
+		if wildcard {
+			// Wildcards match the rest of the path, slashes included.
+			parts = append(parts, p.Value)
+		} else {
+			parts = append(parts, "url.PathEscape("+p.Value+")")
+		}
+	}
+	if literal != "" {
+		parts = append(parts, strconv.Quote(literal))
+	}
+
+	for name := range params {
+		if !used[name] {
+			return "", fmt.Errorf("api.path %s isn't a parameter of route %s", name, route)
+		}
+	}
+
+	return strings.Join(parts, " + "), nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+)
+
+var (
+	majorVersion   = regexp.MustCompile(`^v[0-9]+$`)
+	nonIdentifiers = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// useMiddlewares makes the clients of data apply middlewares, in order. Their
+// packages are imported under aliases unique among the imports of the client.
+func useMiddlewares(data *config.RGOClientTemplateData, middlewares []config.Middleware) {
+	taken := map[string]bool{data.Service.ServiceRefName: true, "serviceName": true}
+	for _, name := range preludeNames {
+		taken[name] = true
+	}
+	for _, r := range config.Resolvers {
+		taken[r.Alias] = true
+	}
+	for _, imp := range data.PkgImports {
+		taken[imp.Alias] = true
+	}
+
+	aliases := map[string]string{}
+	for _, m := range middlewares {
+		alias, ok := aliases[m.ImportPath]
+		if !ok {
+			alias = uniqueAlias(packageAlias(m.ImportPath), taken)
+			aliases[m.ImportPath] = alias
+			data.MiddlewareImports = append(data.MiddlewareImports, config.RGOImport{Alias: alias, Path: m.ImportPath})
+		}
+
+		data.Middlewares = append(data.Middlewares, alias+"."+m.Func)
+	}
+}
+
+// packageAlias guesses the name of the package at importPath from its last
+// element, skipping a major version suffix (e.g., mw for github.com/acme/mw/v2).
+func packageAlias(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersion.MatchString(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+
+	name = nonIdentifiers.ReplaceAllString(strings.TrimPrefix(name, "go-"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+
+	return name
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+)
+
+// localNames are the names used in the bodies of the generated functions,
+// which their parameters mustn't shadow.
+var localNames = []string{"ctx", "opts", "r", "err", "stream", "c", "m", "fn", "fmt", "getDefaultClient", "jsonResponse"}
+
+// names is a set of Go identifiers declared in the same scope.
+type names map[string]bool
+
+func (n names) reserve(identifiers ...string) {
+	for _, id := range identifiers {
+		n[id] = true
+	}
+}
+
+// add declares name, or name followed by as many underscores as needed for it
+// to be unique, the way thriftgo renames colliding identifiers in kitex_gen.
+// Each of prefixes followed by the chosen name is declared as well.
+func (n names) add(name string, prefixes ...string) string {
+	for ; ; name += "_" {
+		if n[name] {
+			continue
+		}
+
+		free := true
+		for _, p := range prefixes {
+			free = free && !n[p+name]
+		}
+		if !free {
+			continue
+		}
+
+		n.reserve(name)
+		for _, p := range prefixes {
+			n.reserve(p + name)
+		}
+		return name
+	}
+}
+
+// nameIdentifiers renames the methods, exceptions and parameters of service
+// which collide with another identifier of the generated client package.
+// Method and parameter names come from thriftgo, so they match the kitex_gen
+// ones unless they collide. taken are the import aliases of the package.
+func nameIdentifiers(service *config.RGOService, taken map[string]bool) {
+	pkg := names{}
+	for alias := range taken {
+		pkg.reserve(alias)
+	}
+
+	s := service.Name
+	pkg.reserve(
+		service.ServiceRefName,
+		s+"API", s+"Client", "New"+s+"Client", "New"+s+"StreamClient",
+		"Mock"+s+"Client", "NewMock"+s+"Client", "UseMock"+s+"Client",
+		s+"Handler", "NewServer",
+		"SetDefaultClient", "InitDefaultClient", "swapDefaultClient", "getDefaultClient", "newDefaultClient",
+		"defaultClient", "defaultClientMu", "lazyClientOnce", "lazyClient", "lazyClientErr",
+		"transportProtocols", "defaultClientOption", "defaultClientOptions", "defaultStreamClientOptions", "jsonResponse",
+		// The methods of the client and mock types share the package scope,
+		// which keeps them apart from their fields and helper methods.
+		"Client", "StreamClient", "CallCount",
+		// Declared by HTTP clients.
+		"CallOption", "ClientOption", "StatusError", "BaseURLs", "HTTPClient",
+	)
+	for _, m := range service.Methods {
+		if m.StreamType != "" {
+			pkg.reserve(m.StreamType)
+		}
+	}
+
+	// Methods come first: they are the API of the package.
+	for _, m := range service.Methods {
+		m.Name = pkg.add(m.KitexName, "Expect")
+
+		local := names{}
+		local.reserve(localNames...)
+		for _, a := range m.Args {
+			a.Name = local.add(a.Name)
+		}
+	}
+
+	for _, e := range service.Exceptions {
+		e.Name = pkg.add(e.Name, "Is", "As")
+	}
+}
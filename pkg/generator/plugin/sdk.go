@@ -23,8 +23,6 @@ import (
 
 	"github.com/cloudwego-contrib/rgo/pkg/config"
 	"github.com/cloudwego/thriftgo/parser"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 
 	"github.com/cloudwego-contrib/rgo/pkg/consts"
 
@@ -36,13 +34,17 @@ func strToPointer(str string) *string {
 	return &str
 }
 
-func GetRGOPlugin(pluginType, pwd, projectModule, serviceName, formatServiceName string) (*RGOPlugin, error) {
+func GetRGOPlugin(pluginType, pwd, projectModule, serviceName, formatServiceName string, mock, server bool, client config.Client, middlewares []config.Middleware) (*RGOPlugin, error) {
 	rgoPlugin := &RGOPlugin{
 		Type:              pluginType,
 		Pwd:               pwd,
 		ProjectModule:     projectModule,
 		ServiceName:       serviceName,
 		FormatServiceName: formatServiceName,
+		Mock:              mock,
+		Server:            server,
+		Client:            client,
+		Middlewares:       middlewares,
 	}
 
 	return rgoPlugin, nil
@@ -54,6 +56,18 @@ type RGOPlugin struct {
 	ServiceName       string
 	FormatServiceName string
 	Pwd               string
+	Mock              bool
+	Server            bool
+	Client            config.Client
+	Middlewares       []config.Middleware
+	// HTTP generates a client of the routes of the api annotations instead of a Kitex one.
+	HTTP bool
+	// Generic and IDLPath are set by GenerateGenericClient.
+	Generic bool
+	IDLPath string
+	// skipTidy is set by GenerateHTTPClient, which tidies the module once
+	// Kitex has written kitex_gen.
+	skipTidy bool
 }
 
 func (r *RGOPlugin) GetName() string {
@@ -80,13 +94,7 @@ func (r *RGOPlugin) generateEditClientTemplateData(req *plugin.Request) (res *pl
 
 	thrift := req.AST
 
-	for k := range thrift.Services {
-		for i := range thrift.Services[k].Functions {
-			thrift.Services[k].Functions[i].Name = cases.Title(language.Und).String(thrift.Services[k].Functions[i].Name)
-		}
-	}
-
-	templateData, err := r.buildClientTemplateData(serviceName, formatServiceName, thrift)
+	templateData, err := r.buildClientTemplateData(serviceName, formatServiceName, thrift, req.GeneratorParameters)
 	if err != nil {
 		return &plugin.Response{
 			Error: strToPointer(fmt.Sprintf("failed to build client template data: %v", err)),
@@ -139,6 +147,24 @@ func (r *RGOPlugin) generateEditClientTemplateData(req *plugin.Request) (res *pl
 		}
 	}
 
+	err = r.generateMockClientFile(templateData)
+	if err != nil {
+		return &plugin.Response{
+			Error: strToPointer(fmt.Sprintf("failed to generate mock client: %v", err)),
+		}
+	}
+
+	err = r.generateServerFile(templateData, RenderEditServerTemplate)
+	if err != nil {
+		return &plugin.Response{
+			Error: strToPointer(fmt.Sprintf("failed to generate server: %v", err)),
+		}
+	}
+
+	if r.skipTidy {
+		return &plugin.Response{}
+	}
+
 	err = utils.RunGoModTidyInDir(r.Pwd)
 	if err != nil {
 		return &plugin.Response{
@@ -155,13 +181,7 @@ func (r *RGOPlugin) generateBuildClientTemplateData(req *plugin.Request) (res *p
 
 	thrift := req.AST
 
-	for k := range thrift.Services {
-		for i := range thrift.Services[k].Functions {
-			thrift.Services[k].Functions[i].Name = cases.Title(language.Und).String(thrift.Services[k].Functions[i].Name)
-		}
-	}
-
-	templateData, err := r.buildClientTemplateData(serviceName, formatServiceName, thrift)
+	templateData, err := r.buildClientTemplateData(serviceName, formatServiceName, thrift, req.GeneratorParameters)
 	if err != nil {
 		return &plugin.Response{
 			Error: strToPointer(fmt.Sprintf("failed to build client template data: %v", err)),
@@ -169,7 +189,15 @@ func (r *RGOPlugin) generateBuildClientTemplateData(req *plugin.Request) (res *p
 	}
 
 	// Render the client template using the extracted data
-	renderedCode, err := RenderCompileClientTemplate(templateData)
+	render := RenderCompileClientTemplate
+	if r.Generic {
+		render = RenderGenericClientTemplate
+	}
+	if r.HTTP {
+		render = RenderHTTPClientTemplate
+	}
+
+	renderedCode, err := render(templateData)
 	if err != nil {
 		return &plugin.Response{
 			Error: strToPointer(fmt.Sprintf("failed to render ast file: %v", err)),
@@ -214,6 +242,24 @@ func (r *RGOPlugin) generateBuildClientTemplateData(req *plugin.Request) (res *p
 		}
 	}
 
+	err = r.generateMockClientFile(templateData)
+	if err != nil {
+		return &plugin.Response{
+			Error: strToPointer(fmt.Sprintf("failed to generate mock client: %v", err)),
+		}
+	}
+
+	err = r.generateServerFile(templateData, RenderCompileServerTemplate)
+	if err != nil {
+		return &plugin.Response{
+			Error: strToPointer(fmt.Sprintf("failed to generate server: %v", err)),
+		}
+	}
+
+	if r.skipTidy {
+		return &plugin.Response{}
+	}
+
 	err = utils.RunGoModTidyInDir(r.Pwd)
 	if err != nil {
 		return &plugin.Response{
@@ -224,14 +270,73 @@ func (r *RGOPlugin) generateBuildClientTemplateData(req *plugin.Request) (res *p
 	return &plugin.Response{}
 }
 
-func (r *RGOPlugin) buildClientTemplateData(serviceName, formatServiceName string, thriftFile *parser.Thrift) (*config.RGOClientTemplateData, error) {
-	data := &config.RGOClientTemplateData{
-		RGOModuleName:     r.ProjectModule,
-		ServiceName:       serviceName,
-		FormatServiceName: formatServiceName,
-		Imports:           []string{"context", "github.com/cloudwego/kitex/client", "github.com/cloudwego/kitex/client/callopt"},
-		Thrift:            thriftFile,
+// generateMockClientFile writes rgo_mock.go next to rgo_cli.go, or removes a
+// stale one when mocks are disabled.
+func (r *RGOPlugin) generateMockClientFile(data *config.RGOClientTemplateData) error {
+	mockFilePath := filepath.Join(r.Pwd, "rgo_mock.go")
+
+	if !r.Mock {
+		err := os.Remove(mockFilePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	renderedCode, err := RenderMockClientTemplate(data)
+	if err != nil {
+		return err
 	}
 
+	return os.WriteFile(mockFilePath, []byte(renderedCode), 0o644)
+}
+
+// generateServerFile writes rgo_server.go, rendered by render, next to
+// rgo_cli.go, or removes a stale one when the server is disabled. Generic
+// clients have no kitex_gen to serve.
+func (r *RGOPlugin) generateServerFile(data *config.RGOClientTemplateData, render func(*config.RGOClientTemplateData) (string, error)) error {
+	serverFilePath := filepath.Join(r.Pwd, "rgo_server.go")
+
+	if !r.Server || r.Generic || r.HTTP {
+		err := os.Remove(serverFilePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	renderedCode, err := render(data)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(serverFilePath, []byte(renderedCode), 0o644)
+}
+
+func (r *RGOPlugin) buildClientTemplateData(serviceName, formatServiceName string, thriftFile *parser.Thrift, generatorParameters []string) (*config.RGOClientTemplateData, error) {
+	data, err := NewClientTemplateData(r.ProjectModule, serviceName, formatServiceName, thriftFile, generatorParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Client = r.Client
+	if resolver, ok := config.Resolvers[r.Client.Resolver]; ok {
+		data.Resolver = &resolver
+	}
+
+	if r.Generic {
+		if err = useGenericCall(data, r.IDLPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.HTTP {
+		if err = useHTTP(data, thriftFile, generatorParameters, r.ProjectModule+"/kitex_gen"); err != nil {
+			return nil, err
+		}
+	}
+
+	useMiddlewares(data, r.Middlewares)
+
 	return data, nil
 }
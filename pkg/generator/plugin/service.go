@@ -0,0 +1,442 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego/thriftgo/generator/backend"
+	"github.com/cloudwego/thriftgo/generator/golang"
+	"github.com/cloudwego/thriftgo/parser"
+	"github.com/cloudwego/thriftgo/semantic"
+)
+
+// NewClientTemplateData builds the data rendered by the client templates for
+// the first service of ast.
+func NewClientTemplateData(moduleName, serviceName, formatServiceName string, ast *parser.Thrift, generatorParameters []string) (*config.RGOClientTemplateData, error) {
+	service, pkgImports, err := resolveService(ast, generatorParameters, moduleName+"/kitex_gen")
+	if err != nil {
+		return nil, err
+	}
+
+	imports := []string{"context", "github.com/cloudwego/kitex/client"}
+	if service.HasUnary {
+		imports = append(imports, "github.com/cloudwego/kitex/client/callopt")
+	}
+	if service.HasStreaming {
+		imports = append(imports, "github.com/cloudwego/kitex/client/callopt/streamcall", "github.com/cloudwego/kitex/client/streamclient")
+	}
+	for _, m := range service.Methods {
+		if m.StreamType != "" {
+			imports = append(imports, "github.com/cloudwego/kitex/pkg/streaming")
+			break
+		}
+	}
+
+	data := &config.RGOClientTemplateData{
+		RGOModuleName:     moduleName,
+		ServiceName:       serviceName,
+		FormatServiceName: formatServiceName,
+		Imports:           imports,
+		PkgImports:        pkgImports,
+		Service:           service,
+		ClientEnvPrefix:   consts.ClientEnvPrefix + strings.ToUpper(formatServiceName) + "_",
+		Thrift:            ast,
+	}
+
+	return data, nil
+}
+
+// resolveService resolves the first service of ast with thriftgo's Go backend,
+// the same way Kitex does when generating kitex_gen, so that the signatures
+// rendered by rgo match the client they forward to. generatorParameters are
+// the thriftgo Go backend options, packagePrefix is used when they don't set
+// package_prefix.
+func resolveService(ast *parser.Thrift, generatorParameters []string, packagePrefix string) (*config.RGOService, []config.RGOImport, error) {
+	if len(ast.Services) == 0 {
+		return nil, nil, fmt.Errorf("no service found in %s", ast.Filename)
+	}
+
+	scope, err := buildScope(ast, generatorParameters, packagePrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svc := scope.Services()[0]
+	pkg := scope.Includes().ByIndex(0)
+	imports := &importSet{}
+
+	service := &config.RGOService{
+		Name:              svc.GoName().String(),
+		PkgRefName:        pkg.PackageName,
+		ServiceImportPath: pkg.ImportPath + "/" + strings.ToLower(svc.GoName().String()),
+	}
+	imports.add(pkg, importUse{})
+
+	src := newIDLSource(ast.Filename)
+	service.Doc = withRef(commentText(ast.Services[0].ReservedComments), src.ref(src.serviceLine(ast.Services[0].Name)))
+
+	var names []string
+	for _, f := range svc.Functions() {
+		names = append(names, f.Name)
+	}
+	lines := src.functionLines(ast.Services[0].Name, names)
+
+	for i, f := range svc.Functions() {
+		st := f.Streaming()
+
+		method := &config.RGOMethod{
+			Name:            f.GoName().String(),
+			KitexName:       f.GoName().String(),
+			RawName:         f.Name,
+			Void:            f.Void,
+			Oneway:          f.Oneway,
+			Streaming:       st.Mode,
+			ClientStreaming: st.ClientStreaming,
+			ServerStreaming: st.ServerStreaming,
+		}
+
+		if st.ClientStreaming || st.ServerStreaming {
+			method.StreamType = service.Name + "_" + method.Name + "Client"
+		}
+
+		if st.IsStreaming {
+			service.HasStreaming = true
+		} else {
+			service.HasUnary = true
+		}
+
+		// Handlers of client or server streaming methods get the responses
+		// from their stream, and so do the requests of client streaming ones.
+		respUse := importUse{signatures: true, handler: method.StreamType == ""}
+		argUse := importUse{signatures: true, handler: !st.ClientStreaming}
+		if method.StreamType != "" {
+			imports.add(pkg, importUse{handler: true})
+		}
+
+		if !f.Void {
+			method.Resp = f.ResponseGoTypeName().String()
+			imports.addType(scope, f.FunctionType, respUse)
+		}
+
+		var argDocs []argDoc
+		for _, a := range f.Arguments() {
+			method.Args = append(method.Args, &config.RGOParameter{
+				Name: a.GoName().String(),
+				Type: a.GoTypeName().String(),
+			})
+			argDocs = append(argDocs, argDoc{name: a.GoName().String(), comments: a.ReservedComments})
+			imports.addType(scope, a.Type, argUse)
+		}
+		method.Doc = methodDoc(f.ReservedComments, argDocs, src.ref(lines[i]))
+
+		for _, t := range f.Throws() {
+			addException(service, t.GoTypeName().Deref().String())
+			imports.addType(scope, t.Type, importUse{exceptions: true})
+		}
+
+		service.Methods = append(service.Methods, method)
+	}
+
+	taken := renameClashingImports(service, imports.imports)
+
+	// The client package is named after the service, which may be the name of
+	// the package defining its types as well (e.g., service Echo in namespace echo).
+	service.ServiceRefName = strings.ToLower(service.Name)
+	if taken[service.ServiceRefName] {
+		service.ServiceRefName = uniqueAlias(service.ServiceRefName+"service", taken)
+	}
+
+	nameIdentifiers(service, taken)
+
+	for _, m := range service.Methods {
+		if m.StreamType != "" {
+			m.HandlerStreamType = service.PkgRefName + "." + service.Name + "_" + m.KitexName + "Server"
+		}
+	}
+
+	return service, imports.imports, nil
+}
+
+// buildScope builds the thriftgo Go backend scope of a fake file including
+// ast, which makes every type of ast come out qualified with its package.
+func buildScope(ast *parser.Thrift, generatorParameters []string, packagePrefix string) (*golang.Scope, error) {
+	cu := golang.NewCodeUtils(backend.LogFunc{
+		Info:      func(v ...interface{}) {},
+		Warn:      func(v ...interface{}) {},
+		MultiWarn: func(warns []string) {},
+	})
+	if err := cu.HandleOptions(generatorParameters); err != nil {
+		return nil, err
+	}
+	if cu.GetPackagePrefix() == "" {
+		cu.SetPackagePrefix(packagePrefix)
+	}
+
+	ref, _, _ := cu.ParseNamespace(ast)
+	fake := copyTreeWithRef(ast, ref)
+	if err := semantic.ResolveSymbols(fake); err != nil {
+		return nil, fmt.Errorf("failed to resolve symbols of %s: %v", ast.Filename, err)
+	}
+
+	used := true
+	fake.ForEachInclude(func(v *parser.Include) bool {
+		v.Used = &used
+		return true
+	})
+
+	scope, err := golang.BuildScope(cu, fake)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build scope for %s: %v", ast.Filename, err)
+	}
+	cu.SetRootScope(scope)
+
+	return scope, nil
+}
+
+// preludeNames are the names a kitex_gen package alias mustn't take in the
+// generated code: the packages imported by the client templates, and the
+// parameters of the generated functions, which would shadow the package.
+var preludeNames = []string{
+	"context", "client", "callopt", "streamcall", "streamclient", "streaming",
+	"errors", "fmt", "os", "strings", "sync", "time", "transport", "genericclient", "generic",
+	"server", "ctx", "opts", "r", "err", "stream", "c",
+}
+
+// renameClashingImports renames the kitex_gen packages whose alias is one of
+// preludeNames, numbering them the way thriftgo numbers clashing aliases, and
+// updates the types of service referring to them. It returns the aliases
+// taken in the generated code.
+func renameClashingImports(service *config.RGOService, imports []config.RGOImport) map[string]bool {
+	taken := map[string]bool{}
+	for _, name := range preludeNames {
+		taken[name] = true
+	}
+	for _, r := range config.Resolvers {
+		taken[r.Alias] = true
+	}
+
+	var clashing []int
+	for i, imp := range imports {
+		if taken[imp.Alias] {
+			clashing = append(clashing, i)
+			continue
+		}
+		taken[imp.Alias] = true
+	}
+
+	for _, i := range clashing {
+		old := imports[i].Alias
+		alias := uniqueAlias(old, taken)
+		imports[i].Alias = alias
+
+		ref := regexp.MustCompile(`\b` + old + `\.`)
+		rename := func(typ string) string {
+			return ref.ReplaceAllString(typ, alias+".")
+		}
+
+		if service.PkgRefName == old {
+			service.PkgRefName = alias
+		}
+		for _, m := range service.Methods {
+			m.Resp = rename(m.Resp)
+			for _, a := range m.Args {
+				a.Type = rename(a.Type)
+			}
+		}
+		for _, e := range service.Exceptions {
+			e.Type = rename(e.Type)
+		}
+	}
+
+	return taken
+}
+
+// uniqueAlias returns name, or name followed by the first number making it
+// unique, and marks it taken.
+func uniqueAlias(name string, taken map[string]bool) string {
+	alias := name
+	for i := 0; taken[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[alias] = true
+
+	return alias
+}
+
+// addException records an exception type once. Exceptions sharing a name but
+// defined in different packages are told apart by prefixing the package.
+func addException(service *config.RGOService, typ string) {
+	name := typ[strings.LastIndex(typ, ".")+1:]
+
+	for _, e := range service.Exceptions {
+		if e.Type == typ {
+			return
+		}
+		if e.Name == name {
+			pkg := typ[:strings.LastIndex(typ, ".")]
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+	}
+
+	service.Exceptions = append(service.Exceptions, &config.RGOException{Name: name, Type: typ})
+}
+
+type importSet struct {
+	imports []config.RGOImport
+}
+
+// importUse tells which generated declarations reference a package. The
+// service references the packages no use is set for.
+type importUse struct {
+	signatures bool // the signatures of the client methods
+	exceptions bool // the exception types
+	handler    bool // the handler interface
+}
+
+func (s *importSet) add(inc *golang.Include, use importUse) {
+	for i := range s.imports {
+		if s.imports[i].Path == inc.ImportPath {
+			s.imports[i].InSignatures = s.imports[i].InSignatures || use.signatures
+			s.imports[i].InExceptions = s.imports[i].InExceptions || use.exceptions
+			s.imports[i].InHandler = s.imports[i].InHandler || use.handler
+			return
+		}
+	}
+
+	s.imports = append(s.imports, config.RGOImport{
+		Alias:        inc.PackageName,
+		Path:         inc.ImportPath,
+		InSignatures: use.signatures,
+		InExceptions: use.exceptions,
+		InHandler:    use.handler,
+	})
+}
+
+func (s *importSet) addType(scope *golang.Scope, t *parser.Type, use importUse) {
+	switch t.Name {
+	case "void", "bool", "byte", "i8", "i16", "i32", "i64", "double", "string", "binary":
+	case "map":
+		s.addType(scope, t.KeyType, use)
+		s.addType(scope, t.ValueType, use)
+	case "set", "list":
+		s.addType(scope, t.ValueType, use)
+	default:
+		if ref := t.GetReference(); ref != nil {
+			s.add(scope.Includes().ByIndex(int(ref.GetIndex())), use)
+		}
+	}
+}
+
+// avoidIncludeConflict renames ast when it includes a file with the same base
+// name, which would otherwise make both share a reference name.
+func avoidIncludeConflict(ast *parser.Thrift, ref string) (*parser.Thrift, string) {
+	fn := filepath.Base(ast.Filename)
+	for _, inc := range ast.Includes {
+		if filepath.Base(inc.Path) == fn {
+			ref = "kitex_faked_idl"
+			faked := *ast
+			faked.Filename = filepath.ToSlash(filepath.Join(filepath.Dir(faked.Filename), ref+".thrift"))
+			if _, ok := ast.GetNamespace("go"); !ok {
+				faked.Namespaces = append(faked.Namespaces, &parser.Namespace{
+					Language: "go",
+					Name:     ast.GetNamespaceOrReferenceName("go"),
+				})
+			}
+			return &faked, ref
+		}
+	}
+
+	return ast, ref
+}
+
+func copyTreeWithRef(ast *parser.Thrift, ref string) *parser.Thrift {
+	ast, ref = avoidIncludeConflict(ast, ref)
+
+	t := &parser.Thrift{
+		Filename: ast.Filename,
+		Namespaces: []*parser.Namespace{
+			{Language: "*", Name: "fake"},
+		},
+	}
+	t.Includes = append(t.Includes, &parser.Include{Path: ast.Filename, Reference: ast})
+	t.Includes = append(t.Includes, ast.Includes...)
+
+	for _, s := range ast.Services {
+		ss := &parser.Service{
+			Name:    s.Name,
+			Extends: s.Extends,
+		}
+		for _, f := range s.Functions {
+			ss.Functions = append(ss.Functions, copyFunctionWithRef(f, ref))
+		}
+		t.Services = append(t.Services, ss)
+	}
+
+	return t
+}
+
+func copyFunctionWithRef(f *parser.Function, ref string) *parser.Function {
+	ff := &parser.Function{
+		Name:             f.Name,
+		Oneway:           f.Oneway,
+		Void:             f.Void,
+		FunctionType:     copyTypeWithRef(f.FunctionType, ref),
+		Annotations:      f.Annotations,
+		ReservedComments: f.ReservedComments,
+	}
+	for _, x := range f.Arguments {
+		y := *x
+		y.Type = copyTypeWithRef(x.Type, ref)
+		ff.Arguments = append(ff.Arguments, &y)
+	}
+	for _, x := range f.Throws {
+		y := *x
+		y.Type = copyTypeWithRef(x.Type, ref)
+		ff.Throws = append(ff.Throws, &y)
+	}
+
+	return ff
+}
+
+func copyTypeWithRef(t *parser.Type, ref string) *parser.Type {
+	switch t.Name {
+	case "void", "bool", "byte", "i8", "i16", "i32", "i64", "double", "string", "binary":
+		return t
+	case "map":
+		return &parser.Type{
+			Name:      t.Name,
+			KeyType:   copyTypeWithRef(t.KeyType, ref),
+			ValueType: copyTypeWithRef(t.ValueType, ref),
+		}
+	case "set", "list":
+		return &parser.Type{
+			Name:      t.Name,
+			ValueType: copyTypeWithRef(t.ValueType, ref),
+		}
+	default:
+		if strings.Contains(t.Name, ".") {
+			return &parser.Type{Name: t.Name}
+		}
+		return &parser.Type{Name: ref + "." + t.Name}
+	}
+}
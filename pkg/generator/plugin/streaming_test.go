@@ -0,0 +1,325 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego/kitex/tool/cmd/kitex/sdk"
+	thriftgoplugin "github.com/cloudwego/thriftgo/plugin"
+)
+
+const streamingModule = "rgo/echo"
+
+// streamingServerTest runs in the generated module. It serves the streaming
+// IDL with the generated in-process server and calls it through the rgo client.
+const streamingServerTest = `package echo_client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/kitex/client"
+	"github.com/cloudwego/kitex/client/streamclient"
+	"github.com/cloudwego/kitex/server"
+
+	echo "rgo/echo/kitex_gen/echo"
+	"rgo/echo/mw"
+)
+
+type handler struct{}
+
+var _ EchoHandler = (*handler)(nil)
+
+func (*handler) Bidi(stream echo.Echo_BidiServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(&echo.EchoResponse{Message: req.Message}); err != nil {
+			return err
+		}
+	}
+}
+
+func (*handler) Upload(stream echo.Echo_UploadServer) error {
+	var msg string
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&echo.EchoResponse{Message: msg})
+		}
+		if err != nil {
+			return err
+		}
+		msg += req.Message
+	}
+}
+
+func (*handler) Download(req *echo.EchoRequest, stream echo.Echo_DownloadServer) error {
+	for _, c := range req.Message {
+		if err := stream.Send(&echo.EchoResponse{Message: string(c)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*handler) Unary(ctx context.Context, req *echo.EchoRequest) (*echo.EchoResponse, error) {
+	return &echo.EchoResponse{Message: req.Message}, nil
+}
+
+func (*handler) Ping(ctx context.Context, req *echo.EchoRequest) (*echo.EchoResponse, error) {
+	return &echo.EchoResponse{Message: req.Message}, nil
+}
+
+func TestStreaming(t *testing.T) {
+	addr, err := net.ResolveTCPAddr("tcp", os.Getenv("RGO_ECHO_CLIENT_HOSTPORTS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svr := NewServer(new(handler), server.WithServiceAddr(addr))
+	go svr.Run()
+	defer svr.Stop()
+	time.Sleep(200 * time.Millisecond)
+
+	serviceClient, err := NewEchoClient("echo", client.WithHostPorts(addr.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamClient, err := NewEchoStreamClient("echo", streamclient.WithHostPorts(addr.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &EchoClient{Client: serviceClient, StreamClient: streamClient}
+	ctx := context.Background()
+
+	bidi, err := c.Bidi(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"a", "b"} {
+		if err = bidi.Send(&echo.EchoRequest{Message: msg}); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := bidi.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Message != msg {
+			t.Errorf("Bidi: got %q, want %q", resp.Message, msg)
+		}
+	}
+	bidi.Close()
+
+	upload, err := c.Upload(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"a", "b", "c"} {
+		if err = upload.Send(&echo.EchoRequest{Message: msg}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := upload.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "abc" {
+		t.Errorf("Upload: got %q, want %q", resp.Message, "abc")
+	}
+
+	download, err := c.Download(ctx, &echo.EchoRequest{Message: "xyz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for {
+		resp, err := download.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got += resp.Message
+	}
+	if got != "xyz" {
+		t.Errorf("Download: got %q, want %q", got, "xyz")
+	}
+
+	resp, err = c.Unary(ctx, &echo.EchoRequest{Message: "unary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "unary" {
+		t.Errorf("Unary: got %q, want %q", resp.Message, "unary")
+	}
+
+	resp, err = c.Ping(ctx, &echo.EchoRequest{Message: "ping"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "ping" {
+		t.Errorf("Ping: got %q, want %q", resp.Message, "ping")
+	}
+
+	// The default client reaches the server through RGO_ECHO_CLIENT_HOSTPORTS.
+	resp, err = Ping(ctx, &echo.EchoRequest{Message: "ping"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "ping" {
+		t.Errorf("Ping: got %q, want %q", resp.Message, "ping")
+	}
+
+	if err = InitDefaultClient(client.WithHostPorts(addr.String())); err != nil {
+		t.Fatal(err)
+	}
+	defer SetDefaultClient(nil)
+
+	resp, err = Ping(ctx, &echo.EchoRequest{Message: "init"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "init" {
+		t.Errorf("Ping: got %q, want %q", resp.Message, "init")
+	}
+
+	if atomic.LoadInt32(&mw.Calls) == 0 {
+		t.Error("the middleware set in the config wasn't called")
+	}
+}
+`
+
+// streamingMiddleware is the middleware set in the config of the generated client.
+const streamingMiddleware = `package mw
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/cloudwego/kitex/pkg/endpoint"
+)
+
+var Calls int32
+
+func Count(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, req, resp interface{}) error {
+		atomic.AddInt32(&Calls, 1)
+		return next(ctx, req, resp)
+	}
+}
+`
+
+// TestStreamingClient generates the build-period client and server of a
+// streaming IDL and runs the client against the server. It is skipped when the Kitex
+// dependencies of the generated module can't be fetched.
+func TestStreamingClient(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping generation test in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+
+	dir := t.TempDir()
+	idlDir := filepath.Join(dir, "idl")
+	genDir := filepath.Join(dir, "echo_client")
+
+	for _, name := range []string{"streaming.thrift", "base.thrift"} {
+		content, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.MkdirAll(idlDir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(idlDir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(genDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	goMod := "module " + streamingModule + "\n\ngo 1.18\n\nrequire github.com/cloudwego/kitex v0.10.3\n"
+	if err := os.WriteFile(filepath.Join(genDir, consts.GoMod), []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile(filepath.Join("..", "..", "..", "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(genDir, "go.sum"), goSum, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.MkdirAll(filepath.Join(genDir, "mw"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(genDir, "mw", "mw.go"), []byte(streamingMiddleware), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	middlewares := []config.Middleware{{Name: "count", ImportPath: streamingModule + "/mw", Func: "Count"}}
+	rgoPlugin, err := GetRGOPlugin(consts.BuildPeriod, genDir, streamingModule, "echo", "echo_client", false, true, config.Client{}, middlewares)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = sdk.RunKitexTool(genDir, []thriftgoplugin.SDKPlugin{rgoPlugin}, "--module", streamingModule, filepath.Join(idlDir, "streaming.thrift"))
+	if err != nil {
+		t.Skipf("failed to generate code, the kitex dependencies are probably unavailable: %v", err)
+	}
+
+	if err = os.WriteFile(filepath.Join(genDir, "rgo_streaming_test.go"), []byte(streamingServerTest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	cmd := exec.Command("go", "test", "-count=1", ".")
+	cmd.Dir = genDir
+	cmd.Env = append(os.Environ(), "RGO_ECHO_CLIENT_HOSTPORTS="+addr)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated streaming client test failed: %v\n%s", err, output)
+	}
+}
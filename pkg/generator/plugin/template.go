@@ -24,31 +24,414 @@ import (
 	"github.com/cloudwego-contrib/rgo/pkg/config"
 )
 
-const defaultRGOEditClientTemplate = `package {{.FormatServiceName}}
+// defaultRGOSignatureTemplate defines the method signatures and the default
+// client shared by all client templates, so that they can't drift apart.
+const defaultRGOSignatureTemplate = `
+{{- define "params" -}}
+ctx context.Context, {{if not .ClientStreaming}}{{range .Args}}{{.Name}} {{.Type}}, {{end}}{{end -}}
+opts ...{{if .Route}}CallOption{{else if .Streaming}}streamcall.Option{{else}}callopt.Option{{end}}
+{{- end -}}
+
+{{- define "clientOption" -}}
+{{if .HTTP}}ClientOption{{else}}client.Option{{end}}
+{{- end -}}
+
+{{- define "results" -}}
+{{- if .StreamType -}}
+(stream {{.StreamType}}, err error)
+{{- else -}}
+({{if not .Void}}r {{.Resp}}, {{end}}err error)
+{{- end -}}
+{{- end -}}
+
+{{- define "args" -}}
+ctx, {{if not .ClientStreaming}}{{range .Args}}{{.Name}}, {{end}}{{end}}opts...
+{{- end -}}
+
+{{- define "doc" -}}
+{{range .}}//{{if .}} {{.}}{{end}}
+{{end}}
+{{- end -}}
+
+{{- define "api" -}}
+type {{.Service.Name}}API interface {
+{{- range .Service.Methods}}
+{{- range .Doc}}
+	//{{if .}} {{.}}{{end}}
+{{- end}}
+	{{.Name}}({{template "params" .}}) {{template "results" .}}
+{{- end}}
+}
+{{- end -}}
+
+{{- define "handler" -}}
+// {{.Service.Name}}Handler is implemented by the handler of {{.ServiceName}} served by NewServer.
+type {{.Service.Name}}Handler interface {
+{{- range .Service.Methods}}
+{{- range .Doc}}
+	//{{if .}} {{.}}{{end}}
+{{- end}}
+	{{- if .HandlerStreamType}}
+	{{.KitexName}}({{if .ServerStreaming}}{{if not .ClientStreaming}}{{range .Args}}{{.Name}} {{.Type}}, {{end}}{{end}}{{end}}stream {{.HandlerStreamType}}) (err error)
+	{{- else}}
+	{{.KitexName}}(ctx context.Context{{range .Args}}, {{.Name}} {{.Type}}{{end}}) ({{if not .Void}}r {{.Resp}}, {{end}}err error)
+	{{- end}}
+{{- end}}
+}
+{{- end -}}
+
+{{- define "serverImports" -}}
+{{- $ctx := false}}
+{{- range .Service.Methods}}{{if not .HandlerStreamType}}{{$ctx = true}}{{end}}{{end}}
+{{- if $ctx}}
+	"context"
+{{- end}}
+	"github.com/cloudwego/kitex/server"
+{{- range .PkgImports }}
+{{- if .InHandler }}
+	{{.Alias}} "{{.Path}}"
+{{- end }}
+{{- end }}
+{{- end -}}
+
+{{- define "streamTypes" -}}
+{{- range .Service.Methods}}
+{{- if .StreamType}}
+
+// {{.StreamType}} is the stream returned by {{.Name}}.
+type {{.StreamType}} interface {
+	streaming.Stream
+	{{- if .ClientStreaming}}
+	Send({{range .Args}}{{.Type}}{{end}}) error
+	{{- end}}
+	{{- if .ServerStreaming}}
+	Recv() ({{.Resp}}, error)
+	{{- end}}
+	{{- if and .ClientStreaming (not .ServerStreaming)}}
+	CloseAndRecv() ({{.Resp}}, error)
+	{{- end}}
+}
+{{- end}}
+{{- end}}
+{{- end -}}
+
+{{- define "exceptionType" -}}
+// {{.Name}} is an exception declared by the service, returned as the error of the methods throwing it.
+type {{.Name}} = {{.Type}}
+{{- end -}}
+
+{{- define "defaultClient" -}}
+var (
+	defaultClientMu sync.RWMutex
+	// defaultClient is set by SetDefaultClient and takes precedence over lazyClient.
+	defaultClient {{.Service.Name}}API
+
+	lazyClientOnce sync.Once
+	lazyClient     {{.Service.Name}}API
+	lazyClientErr  error
+)
+
+// SetDefaultClient replaces the client used by the package-level functions. A nil c restores the
+// client created from rgo_config.yaml.
+func SetDefaultClient(c {{.Service.Name}}API) {
+	swapDefaultClient(c)
+}
+
+// InitDefaultClient creates the client used by the package-level functions, with opts appended to
+// the options set in rgo_config.yaml.
+func InitDefaultClient(opts ...{{template "clientOption" .}}) error {
+	c, err := newDefaultClient(opts...)
+	if err != nil {
+		return err
+	}
+	SetDefaultClient(c)
+	return nil
+}
+
+func swapDefaultClient(c {{.Service.Name}}API) {{.Service.Name}}API {
+	defaultClientMu.Lock()
+	defer defaultClientMu.Unlock()
+	prev := defaultClient
+	defaultClient = c
+	return prev
+}
+
+// getDefaultClient returns the client set by SetDefaultClient, or else the one created from
+// rgo_config.yaml on first use. A failed creation is reported by every call.
+func getDefaultClient() ({{.Service.Name}}API, error) {
+	defaultClientMu.RLock()
+	c := defaultClient
+	defaultClientMu.RUnlock()
+	if c != nil {
+		return c, nil
+	}
+
+	lazyClientOnce.Do(func() {
+		lazyClient, lazyClientErr = newDefaultClient()
+	})
+	return lazyClient, lazyClientErr
+}
+
+func newDefaultClient(opts ...{{template "clientOption" .}}) ({{.Service.Name}}API, error) {
+	{{- if .HTTP}}
+	hostPorts := defaultClientOption("HOSTPORTS", {{printf "%q" (join .Client.HostPorts ",")}})
+	if hostPorts == "" {
+		return nil, fmt.Errorf("failed to create the default client of {{.ServiceName}}: no hostports, set {{.ClientEnvPrefix}}HOSTPORTS")
+	}
+	defaultOpts, err := defaultClientOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the default client of {{.ServiceName}}: %w", err)
+	}
+	c, err := New{{.Service.Name}}Client(strings.Split(hostPorts, ","), append(defaultOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the default client of {{.ServiceName}}: %w", err)
+	}
+	return c, nil
+	{{- else}}
+	defaultOpts, err := defaultClientOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the default client of {{.ServiceName}}: %w", err)
+	}
+	serviceClient, err := New{{.Service.Name}}Client("{{.ServiceName}}", append(defaultOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the default client of {{.ServiceName}}: %w", err)
+	}
+	{{- if .Service.HasStreaming}}
+	streamOpts, err := defaultStreamClientOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the default stream client of {{.ServiceName}}: %w", err)
+	}
+	streamClient, err := New{{.Service.Name}}StreamClient("{{.ServiceName}}", streamOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the default stream client of {{.ServiceName}}: %w", err)
+	}
+	return &{{.Service.Name}}Client{Client: serviceClient, StreamClient: streamClient}, nil
+	{{- else}}
+	return &{{.Service.Name}}Client{Client: serviceClient}, nil
+	{{- end}}
+	{{- end}}
+}
+{{- end -}}
+
+{{- define "httpClientFields" -}}
+// BaseURLs are the URLs the routes are appended to, used in turn (e.g., http://127.0.0.1:8888).
+	BaseURLs []string
+	// HTTPClient sends the requests.
+	HTTPClient *http.Client
+{{- end -}}
+
+{{- define "httpTypes" -}}
+// CallOption customizes the request of a single call, e.g. to set a header.
+type CallOption func(*http.Request)
+
+// ClientOption customizes the client created by New{{.Service.Name}}Client.
+type ClientOption func(*{{.Service.Name}}Client)
 
+// StatusError is returned by the calls answered with a status other than 2xx.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("{{.ServiceName}} responded %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+{{- end -}}
+
+{{- define "clientOptions" -}}
+var transportProtocols = map[string]transport.Protocol{
+	"purepayload":     transport.PurePayload,
+	"ttheader":        transport.TTHeader,
+	"framed":          transport.Framed,
+	"ttheader_framed": transport.TTHeaderFramed,
+	"grpc":            transport.GRPC,
+}
+
+// defaultClientOption returns the {{.ClientEnvPrefix}}<name> environment variable if it is set, def otherwise.
+func defaultClientOption(name, def string) string {
+	if v, ok := os.LookupEnv("{{.ClientEnvPrefix}}" + name); ok {
+		return v
+	}
+	return def
+}
+
+// defaultClientOptions returns the options of the default client set in rgo_config.yaml,
+// overridden by the {{.ClientEnvPrefix}}* environment variables, or an error if one of them is invalid.
+func defaultClientOptions() ([]client.Option, error) {
+	var opts []client.Option
+	if hostPorts := defaultClientOption("HOSTPORTS", {{printf "%q" (join .Client.HostPorts ",")}}); hostPorts != "" {
+		opts = append(opts, client.WithHostPorts(strings.Split(hostPorts, ",")...))
+	}{{with .Resolver}} else {
+		opts = append(opts, client.WithResolver({{.New}}))
+	}{{end}}
+	if s := defaultClientOption("RPC_TIMEOUT", {{printf "%q" .Client.RPCTimeout}}); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid {{.ClientEnvPrefix}}RPC_TIMEOUT %q: %w", s, err)
+		}
+		opts = append(opts, client.WithRPCTimeout(d))
+	}
+	if s := defaultClientOption("CONNECT_TIMEOUT", {{printf "%q" .Client.ConnectTimeout}}); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid {{.ClientEnvPrefix}}CONNECT_TIMEOUT %q: %w", s, err)
+		}
+		opts = append(opts, client.WithConnectTimeout(d))
+	}
+	if s := defaultClientOption("TRANSPORT", {{printf "%q" .Client.Transport}}); s != "" {
+		p, ok := transportProtocols[s]
+		if !ok {
+			return nil, fmt.Errorf("unknown {{.ClientEnvPrefix}}TRANSPORT %q", s)
+		}
+		opts = append(opts, client.WithTransportProtocol(p))
+	}
+	return opts, nil
+}
+{{- if .Service.HasStreaming}}
+
+// defaultStreamClientOptions is the streaming counterpart of defaultClientOptions. Streams always use gRPC
+// and have no RPC timeout.
+func defaultStreamClientOptions() ([]streamclient.Option, error) {
+	var opts []streamclient.Option
+	if hostPorts := defaultClientOption("HOSTPORTS", {{printf "%q" (join .Client.HostPorts ",")}}); hostPorts != "" {
+		opts = append(opts, streamclient.WithHostPorts(strings.Split(hostPorts, ",")...))
+	}{{with .Resolver}} else {
+		opts = append(opts, streamclient.WithResolver({{.New}}))
+	}{{end}}
+	if s := defaultClientOption("CONNECT_TIMEOUT", {{printf "%q" .Client.ConnectTimeout}}); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid {{.ClientEnvPrefix}}CONNECT_TIMEOUT %q: %w", s, err)
+		}
+		opts = append(opts, streamclient.WithConnectTimeout(d))
+	}
+	return opts, nil
+}
+{{- end}}
+{{- end -}}
+
+{{- define "middlewareOptions" -}}
+{{- if .Middlewares}}
+	// The middlewares set in rgo_config.yaml come first in the chain.
+	opts = append([]client.Option{
+		{{- range .Middlewares}}
+		client.WithMiddleware({{.}}),
+		{{- end}}
+	}, opts...)
+{{- end}}
+{{- end -}}
+
+{{- define "packageFunc" -}}
+{{template "doc" .Doc}}func {{.Name}}({{template "params" .}}) {{template "results" .}} {
+	c, err := getDefaultClient()
+	if err != nil {
+		return
+	}
+	return c.{{.Name}}({{template "args" .}})
+}
+{{- end -}}
+
+{{- define "imports" -}}
 import (
 	{{- range .Imports }}
 	"{{.}}"
 	{{- end }}
-	"{{.RGOModuleName}}/kitex_gen/{{(index .Namespaces 0).Name}}"
+	{{- if .HTTP }}
+	"fmt"
+	{{- end }}
+	{{- if .Generic }}
+	"github.com/cloudwego/kitex/client/genericclient"
+	{{- else }}
+	{{- range .PkgImports }}
+	{{- if or .InSignatures .InExceptions }}
+	{{.Alias}} "{{.Path}}"
+	{{- end }}
+	{{- end }}
+	{{- if not .HTTP }}
+	{{.Service.ServiceRefName}} "{{.Service.ServiceImportPath}}"
+	{{- end }}
+	{{- end }}
 )
+{{- end -}}
+`
+
+const defaultRGOEditClientTemplate = `package {{.FormatServiceName}}
+
+{{template "imports" .}}
+
+{{template "api" .}}
 
-type {{(index .Services 0).Name}}Client struct {
-	{{(index .Services 0).Name}} {{(index .Namespaces 0).Name}}.{{(index .Services 0).Name}}
+var defaultClient {{.Service.Name}}API
+
+// SetDefaultClient replaces the client used by the package-level functions. A nil c restores the
+// client created from rgo_config.yaml.
+func SetDefaultClient(c {{.Service.Name}}API) {
+}
+
+// InitDefaultClient creates the client used by the package-level functions, with opts appended to
+// the options set in rgo_config.yaml.
+func InitDefaultClient(opts ...{{template "clientOption" .}}) error {
+	return nil
+}
+
+func swapDefaultClient(c {{.Service.Name}}API) {{.Service.Name}}API {
+	return nil
+}
+
+{{- if .HTTP}}
+
+{{template "httpTypes" .}}
+
+{{template "doc" .Service.Doc}}type {{.Service.Name}}Client struct {
+	{{template "httpClientFields"}}
+}
+
+func New{{.Service.Name}}Client(baseURLs []string, opts ...ClientOption) (*{{.Service.Name}}Client, error) {
+	return nil, nil
 }
+{{- else}}
 
-func New{{(index .Services 0).Name}}Client(serviceName string, opts ...client.Option) ({{(index .Services 0).Name}}Client, error) {
-	return {{(index .Services 0).Name}}Client{}, nil
+{{template "doc" .Service.Doc}}type {{.Service.Name}}Client struct {
+	{{- if .Generic}}
+	genericclient.Client
+	{{- else}}
+	{{.Service.ServiceRefName}}.Client
+	{{- if .Service.HasStreaming}}
+	{{.Service.ServiceRefName}}.StreamClient
+	{{- end}}
+	{{- end}}
 }
 
-{{range (index .Services 0).Functions}}
-func (c *{{(index $.Services 0).Name}}Client) {{.Name}}(ctx context.Context, {{range .Arguments}}{{.Name}} *{{(index $.Namespaces 0).Name}}.{{.Type}}, {{end}}opts ...callopt.Option) (*{{(index $.Namespaces 0).Name}}.{{.FunctionType}}, error) {
+func New{{.Service.Name}}Client(serviceName string, opts ...client.Option) ({{if .Generic}}genericclient{{else}}{{.Service.ServiceRefName}}{{end}}.Client, error) {
 	return nil, nil
 }
+{{- if .Service.HasStreaming}}
 
-func {{.Name}}(ctx context.Context, {{range .Arguments}}{{.Name}} *{{(index $.Namespaces 0).Name}}.{{.Type}}, {{end}}opts ...callopt.Option) (*{{(index $.Namespaces 0).Name}}.{{.FunctionType}}, error) {
+func New{{.Service.Name}}StreamClient(serviceName string, opts ...streamclient.Option) ({{.Service.ServiceRefName}}.StreamClient, error) {
 	return nil, nil
 }
+{{- end}}
+{{- end}}
+{{template "streamTypes" .}}
+{{range .Service.Exceptions}}
+{{template "exceptionType" .}}
+
+func Is{{.Name}}(err error) bool {
+	return false
+}
+
+func As{{.Name}}(err error) (*{{.Name}}, bool) {
+	return nil, false
+}
+{{end}}
+{{range .Service.Methods}}
+{{template "doc" .Doc}}func (c *{{$.Service.Name}}Client) {{.Name}}({{template "params" .}}) {{template "results" .}} {
+	return
+}
+
+{{template "doc" .Doc}}func {{.Name}}({{template "params" .}}) {{template "results" .}} {
+	return
+}
 {{end}}
 `
 
@@ -58,69 +441,499 @@ import (
 	{{- range .Imports }}
 	"{{.}}"
 	{{- end }}
-	"{{.RGOModuleName}}/kitex_gen/{{(index .Namespaces 0).Name}}"
-	"{{.RGOModuleName}}/kitex_gen/{{(index .Namespaces 0).Name}}/{{ToLower (index .Services 0).Name}}"
+	{{- if .Service.Exceptions }}
+	"errors"
+	{{- end }}
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"github.com/cloudwego/kitex/transport"
+	{{- with .Resolver }}
+	{{.Alias}} "{{.ImportPath}}"
+	{{- end }}
+	{{- range .MiddlewareImports }}
+	{{.Alias}} "{{.Path}}"
+	{{- end }}
+	{{- range .PkgImports }}
+	{{- if or .InSignatures .InExceptions }}
+	{{.Alias}} "{{.Path}}"
+	{{- end }}
+	{{- end }}
+	{{.Service.ServiceRefName}} "{{.Service.ServiceImportPath}}"
 )
 
-var defaultClient *{{(index .Services 0).Name}}Client
+{{template "api" .}}
 
-func init() {
-	defaultClient = &{{(index .Services 0).Name}}Client{}
-	defaultClient.Client, _ = New{{(index .Services 0).Name}}Client("{{.ServiceName}}")
-}
+{{template "defaultClient" .}}
+
+{{template "clientOptions" .}}
 
-type {{(index .Services 0).Name}}Client struct {
-	{{ToLower (index .Services 0).Name}}.Client
+{{template "doc" .Service.Doc}}type {{.Service.Name}}Client struct {
+	{{.Service.ServiceRefName}}.Client
+	{{- if .Service.HasStreaming}}
+	{{.Service.ServiceRefName}}.StreamClient
+	{{- end}}
 }
 
-func New{{(index .Services 0).Name}}Client(serviceName string, opts ...client.Option) ({{ToLower (index .Services 0).Name}}.Client, error) {
-	serviceClient, err := {{ToLower (index .Services 0).Name}}.NewClient(serviceName, opts...)
+func New{{.Service.Name}}Client(serviceName string, opts ...client.Option) ({{.Service.ServiceRefName}}.Client, error) {
+	{{- template "middlewareOptions" .}}
+	serviceClient, err := {{.Service.ServiceRefName}}.NewClient(serviceName, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return serviceClient, nil
 }
+{{- if .Service.HasStreaming}}
+
+// New{{.Service.Name}}StreamClient creates the client used by the streaming methods, which Kitex serves over gRPC.
+func New{{.Service.Name}}StreamClient(serviceName string, opts ...streamclient.Option) ({{.Service.ServiceRefName}}.StreamClient, error) {
+	{{- if .Middlewares}}
+	opts = append([]streamclient.Option{
+		{{- range .Middlewares}}
+		streamclient.WithMiddleware({{.}}),
+		{{- end}}
+	}, opts...)
+	{{- end}}
+	return {{.Service.ServiceRefName}}.NewStreamClient(serviceName, opts...)
+}
+{{- end}}
+{{template "streamTypes" .}}
+{{range .Service.Exceptions}}
+{{template "exceptionType" .}}
+
+// Is{{.Name}} reports whether err is, or wraps, a {{.Name}} exception.
+func Is{{.Name}}(err error) bool {
+	_, ok := As{{.Name}}(err)
+	return ok
+}
+
+// As{{.Name}} returns the {{.Name}} exception carried by err, if any.
+func As{{.Name}}(err error) (*{{.Name}}, bool) {
+	var e *{{.Name}}
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+{{end}}
+{{range .Service.Methods}}
+{{template "doc" .Doc}}func (c *{{$.Service.Name}}Client) {{.Name}}({{template "params" .}}) {{template "results" .}} {
+	return c.{{if .Streaming}}StreamClient{{else}}Client{{end}}.{{.KitexName}}({{template "args" .}})
+}
+
+{{template "packageFunc" .}}
+{{end}}
+`
+
+const defaultRGOGenericClientTemplate = `package {{.FormatServiceName}}
+
+import (
+	{{- range .Imports }}
+	"{{.}}"
+	{{- end }}
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"github.com/cloudwego/kitex/client/genericclient"
+	"github.com/cloudwego/kitex/pkg/generic"
+	"github.com/cloudwego/kitex/transport"
+	{{- with .Resolver }}
+	{{.Alias}} "{{.ImportPath}}"
+	{{- end }}
+	{{- range .MiddlewareImports }}
+	{{.Alias}} "{{.Path}}"
+	{{- end }}
+)
+
+{{template "api" .}}
+
+{{template "defaultClient" .}}
+
+{{template "clientOptions" .}}
+
+// {{.Service.Name}}Client calls {{.ServiceName}} with Kitex's JSON generic call: requests and responses are JSON.
+{{- with .Service.Doc}}
+//
+{{template "doc" .}}
+{{- else}}
+{{end -}}
+type {{.Service.Name}}Client struct {
+	genericclient.Client
+}
 
-{{range (index .Services 0).Functions}}
-func (c *{{(index $.Services 0).Name}}Client) {{.Name}}(ctx context.Context, {{range .Arguments}}{{.Name}} *{{(index $.Namespaces 0).Name}}.{{.Type}}, {{end}}opts ...callopt.Option) (*{{(index $.Namespaces 0).Name}}.{{.FunctionType}}, error) {
-	res, err := c.Client.{{.Name}}(ctx, {{range .Arguments}}{{.Name}}, {{end}}opts...)
+// New{{.Service.Name}}Client creates a generic client from the IDL at {{.IDLPath}},
+// or at {{.ClientEnvPrefix}}IDL_PATH if it is set.
+func New{{.Service.Name}}Client(serviceName string, opts ...client.Option) (genericclient.Client, error) {
+	{{- template "middlewareOptions" .}}
+	idlPath := defaultClientOption("IDL_PATH", {{printf "%q" .IDLPath}})
+	p, err := generic.NewThriftFileProvider(idlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idl %s: %w", idlPath, err)
+	}
+	g, err := generic.JSONThriftGeneric(p)
 	if err != nil {
 		return nil, err
 	}
-	return res, nil
+	return genericclient.NewClient(serviceName, g, opts...)
 }
 
-func {{.Name}}(ctx context.Context, {{range .Arguments}}{{.Name}} *{{(index $.Namespaces 0).Name}}.{{.Type}}, {{end}}opts ...callopt.Option) (*{{(index $.Namespaces 0).Name}}.{{.FunctionType}}, error) {
-	res, err := defaultClient.{{.Name}}(ctx, {{range .Arguments}}{{.Name}}, {{end}}opts...)
+func jsonResponse(resp interface{}, err error) (string, error) {
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return res, nil
+	s, _ := resp.(string)
+	return s, nil
 }
+{{range .Service.Methods}}
+{{template "doc" .Doc}}func (c *{{$.Service.Name}}Client) {{.Name}}({{template "params" .}}) {{template "results" .}} {
+	{{- if .Void}}
+	_, err = c.Client.GenericCall(ctx, "{{.RawName}}", {{(index .Args 0).Name}}, opts...)
+	return
+	{{- else}}
+	return jsonResponse(c.Client.GenericCall(ctx, "{{.RawName}}", {{(index .Args 0).Name}}, opts...))
+	{{- end}}
+}
+
+{{template "packageFunc" .}}
 {{end}}
 `
 
-func RenderEditClientTemplate(data *config.RGOClientTemplateData) (string, error) {
-	tmpl, err := template.New("editClientTemplate").Parse(defaultRGOEditClientTemplate)
+const defaultRGOHTTPClientTemplate = `package {{.FormatServiceName}}
+
+import (
+	{{- range .Imports }}
+	"{{.}}"
+	{{- end }}
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	{{- range .PkgImports }}
+	{{.Alias}} "{{.Path}}"
+	{{- end }}
+)
+
+{{template "api" .}}
+
+{{template "defaultClient" .}}
+
+// defaultClientOption returns the {{.ClientEnvPrefix}}<name> environment variable if it is set, def otherwise.
+func defaultClientOption(name, def string) string {
+	if v, ok := os.LookupEnv("{{.ClientEnvPrefix}}" + name); ok {
+		return v
+	}
+	return def
+}
+
+// defaultClientOptions returns the options of the default client set in rgo_config.yaml,
+// overridden by the {{.ClientEnvPrefix}}* environment variables, or an error if one of them is invalid.
+// The RPC timeout bounds whole calls.
+func defaultClientOptions() ([]ClientOption, error) {
+	var opts []ClientOption
+	if s := defaultClientOption("RPC_TIMEOUT", {{printf "%q" .Client.RPCTimeout}}); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid {{.ClientEnvPrefix}}RPC_TIMEOUT %q: %w", s, err)
+		}
+		opts = append(opts, func(c *{{.Service.Name}}Client) {
+			c.HTTPClient.Timeout = d
+		})
+	}
+	if s := defaultClientOption("CONNECT_TIMEOUT", {{printf "%q" .Client.ConnectTimeout}}); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid {{.ClientEnvPrefix}}CONNECT_TIMEOUT %q: %w", s, err)
+		}
+		opts = append(opts, func(c *{{.Service.Name}}Client) {
+			c.HTTPClient.Transport = &http.Transport{
+				Proxy:       http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{Timeout: d}).DialContext,
+			}
+		})
+	}
+	return opts, nil
+}
+
+{{template "httpTypes" .}}
+
+// {{.Service.Name}}Client calls the HTTP routes of {{.ServiceName}} declared by the api annotations of its IDL.
+{{- with .Service.Doc}}
+//
+{{template "doc" .}}
+{{- else}}
+{{end -}}
+type {{.Service.Name}}Client struct {
+	{{template "httpClientFields"}}
+
+	next uint32
+}
+
+// New{{.Service.Name}}Client creates a client sending its requests to baseURLs in turn. Base URLs
+// without scheme (e.g., 127.0.0.1:8888) use http.
+func New{{.Service.Name}}Client(baseURLs []string, opts ...ClientOption) (*{{.Service.Name}}Client, error) {
+	if len(baseURLs) == 0 {
+		return nil, fmt.Errorf("no base url")
+	}
+	c := &{{.Service.Name}}Client{HTTPClient: &http.Client{}}
+	for _, u := range baseURLs {
+		if !strings.Contains(u, "://") {
+			u = "http://" + u
+		}
+		c.BaseURLs = append(c.BaseURLs, strings.TrimSuffix(u, "/"))
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// do sends a request to the next base URL, with body encoded as JSON unless it is nil, and decodes
+// the JSON response into resp unless it is nil.
+func (c *{{.Service.Name}}Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, resp interface{}, opts []CallOption) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+		header.Set("Content-Type", "application/json")
+	}
+
+	u := c.BaseURLs[int((atomic.AddUint32(&c.next, 1)-1)%uint32(len(c.BaseURLs)))] + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
 	if err != nil {
-		return "", err
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	for _, opt := range opts {
+		opt(req)
 	}
 
-	var rendered bytes.Buffer
-	err = tmpl.Execute(&rendered, data)
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return err
 	}
+	defer res.Body.Close()
 
-	return rendered.String(), nil
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return &StatusError{StatusCode: res.StatusCode, Body: data}
+	}
+	if resp == nil || len(data) == 0 {
+		return nil
+	}
+	if err = json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+{{range .Service.Methods}}
+{{template "doc" .Doc}}func (c *{{$.Service.Name}}Client) {{.Name}}({{template "params" .}}) {{template "results" .}} {
+	query := url.Values{}
+	{{- range .Route.Query}}
+	{{- if .List}}
+	for _, v := range {{.Field}} {
+		query.Add("{{.Name}}", {{.Value}})
+	}
+	{{- else if .Optional}}
+	if {{.Field}} != nil {
+		query.Set("{{.Name}}", {{.Value}})
+	}
+	{{- else}}
+	query.Set("{{.Name}}", {{.Value}})
+	{{- end}}
+	{{- end}}
+	header := http.Header{}
+	{{- range .Route.Headers}}
+	{{- if .List}}
+	for _, v := range {{.Field}} {
+		header.Add("{{.Name}}", {{.Value}})
+	}
+	{{- else if .Optional}}
+	if {{.Field}} != nil {
+		header.Set("{{.Name}}", {{.Value}})
+	}
+	{{- else}}
+	header.Set("{{.Name}}", {{.Value}})
+	{{- end}}
+	{{- end}}
+	err = c.do(ctx, "{{.Route.Method}}", {{.Route.Path}}, query, header, {{if .Route.Body}}{{(index .Args 0).Name}}{{else}}nil{{end}}, {{if .Void}}nil{{else}}&r{{end}}, opts)
+	return
+}
+
+{{template "packageFunc" .}}
+{{end}}
+`
+
+const defaultRGOMockClientTemplate = `package {{.FormatServiceName}}
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	{{if and .Service.HasUnary (not .HTTP) -}}
+	"github.com/cloudwego/kitex/client/callopt"
+	{{- end}}
+	{{- if .Service.HasStreaming}}
+	"github.com/cloudwego/kitex/client/callopt/streamcall"
+	{{- end}}
+	{{- range .PkgImports }}
+	{{- if .InSignatures }}
+	{{.Alias}} "{{.Path}}"
+	{{- end }}
+	{{- end }}
+)
+
+// Mock{{.Service.Name}}Client is a programmable fake of {{.Service.Name}}API for unit tests.
+// Calling a method without an expectation returns an error.
+type Mock{{.Service.Name}}Client struct {
+	mu    sync.Mutex
+	calls map[string]int
+{{- range .Service.Methods}}
+	expect{{.Name}} func({{template "params" .}}) {{template "results" .}}
+{{- end}}
+}
+
+var _ {{.Service.Name}}API = (*Mock{{.Service.Name}}Client)(nil)
+
+func NewMock{{.Service.Name}}Client() *Mock{{.Service.Name}}Client {
+	return &Mock{{.Service.Name}}Client{calls: map[string]int{}}
+}
+
+// UseMock{{.Service.Name}}Client makes the package-level functions call c and returns a function
+// restoring the previous default client.
+func UseMock{{.Service.Name}}Client(c {{.Service.Name}}API) (restore func()) {
+	prev := swapDefaultClient(c)
+	return func() {
+		swapDefaultClient(prev)
+	}
+}
+
+// CallCount returns how many times method has been called.
+func (m *Mock{{.Service.Name}}Client) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+{{range .Service.Methods}}
+// Expect{{.Name}} sets the implementation used by {{.Name}}.
+func (m *Mock{{$.Service.Name}}Client) Expect{{.Name}}(fn func({{template "params" .}}) {{template "results" .}}) *Mock{{$.Service.Name}}Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expect{{.Name}} = fn
+	return m
+}
+
+func (m *Mock{{$.Service.Name}}Client) {{.Name}}({{template "params" .}}) {{template "results" .}} {
+	m.mu.Lock()
+	m.calls["{{.Name}}"]++
+	fn := m.expect{{.Name}}
+	m.mu.Unlock()
+
+	if fn == nil {
+		err = fmt.Errorf("Mock{{$.Service.Name}}Client: unexpected call to {{.Name}}")
+		return
+	}
+	return fn({{template "args" .}})
+}
+{{end}}
+`
+
+const defaultRGOEditServerTemplate = `package {{.FormatServiceName}}
+
+import (
+{{- template "serverImports" .}}
+)
+
+{{template "handler" .}}
+
+// NewServer creates a Kitex server of {{.ServiceName}} calling handler.
+func NewServer(handler {{.Service.Name}}Handler, opts ...server.Option) server.Server {
+	return nil
+}
+`
+
+const defaultRGOCompileServerTemplate = `package {{.FormatServiceName}}
+
+import (
+{{- template "serverImports" .}}
+	{{.Service.ServiceRefName}} "{{.Service.ServiceImportPath}}"
+)
+
+{{template "handler" .}}
+
+// NewServer creates a Kitex server of {{.ServiceName}} calling handler.
+func NewServer(handler {{.Service.Name}}Handler, opts ...server.Option) server.Server {
+	return {{.Service.ServiceRefName}}.NewServer(handler, opts...)
+}
+`
+
+func RenderEditClientTemplate(data *config.RGOClientTemplateData) (string, error) {
+	return renderClientTemplate("editClientTemplate", defaultRGOEditClientTemplate, data)
 }
 
 func RenderCompileClientTemplate(data *config.RGOClientTemplateData) (string, error) {
+	return renderClientTemplate("compileClientTemplate", defaultRGOCompileClientTemplate, data)
+}
+
+func RenderGenericClientTemplate(data *config.RGOClientTemplateData) (string, error) {
+	return renderClientTemplate("genericClientTemplate", defaultRGOGenericClientTemplate, data)
+}
+
+func RenderHTTPClientTemplate(data *config.RGOClientTemplateData) (string, error) {
+	return renderClientTemplate("httpClientTemplate", defaultRGOHTTPClientTemplate, data)
+}
+
+func RenderEditServerTemplate(data *config.RGOClientTemplateData) (string, error) {
+	return renderClientTemplate("editServerTemplate", defaultRGOEditServerTemplate, data)
+}
+
+func RenderCompileServerTemplate(data *config.RGOClientTemplateData) (string, error) {
+	return renderClientTemplate("compileServerTemplate", defaultRGOCompileServerTemplate, data)
+}
+
+func RenderMockClientTemplate(data *config.RGOClientTemplateData) (string, error) {
+	return renderClientTemplate("mockClientTemplate", defaultRGOMockClientTemplate, data)
+}
+
+func renderClientTemplate(name, text string, data *config.RGOClientTemplateData) (string, error) {
 	funcMap := template.FuncMap{
 		"ToLower": strings.ToLower,
+		"join":    strings.Join,
+	}
+
+	tmpl, err := template.New(name).Funcs(funcMap).Parse(defaultRGOSignatureTemplate)
+	if err != nil {
+		return "", err
 	}
 
-	tmpl, err := template.New("compileClientTemplate").Funcs(funcMap).Parse(defaultRGOCompileClientTemplate)
+	tmpl, err = tmpl.Parse(text)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,310 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// kitexThriftOptions are the thriftgo options Kitex passes by default.
+var kitexThriftOptions = []string{
+	"naming_style=golint",
+	"ignore_initialisms",
+	"gen_setter",
+	"gen_deep_equal",
+	"compatible_names",
+	"frugal_tag",
+	"thrift_streaming",
+	"no_processor",
+}
+
+// testClients are the client blocks of the IDLs under testdata.
+var testClients = map[string]config.Client{
+	"all_types.thrift": {HostPorts: []string{"127.0.0.1:8888", "127.0.0.1:8889"}, RPCTimeout: "1s", ConnectTimeout: "50ms", Transport: "ttheader"},
+	"streaming.thrift": {Resolver: "dns"},
+}
+
+// testMiddlewares are the middleware chains of the IDLs under testdata.
+var testMiddlewares = map[string][]config.Middleware{
+	"all_types.thrift": {
+		{Name: "auth", ImportPath: "github.com/acme/mw", Func: "Auth"},
+		{Name: "log", ImportPath: "github.com/acme/mw", Func: "Log"},
+		{Name: "errors", ImportPath: "github.com/acme/client/v2", Func: "MapErrors"},
+	},
+	"streaming.thrift": {{Name: "auth", ImportPath: "github.com/acme/mw", Func: "Auth"}},
+	"generic.thrift":   {{Name: "auth", ImportPath: "github.com/acme/mw", Func: "Auth"}},
+}
+
+// genericIDLs are the IDLs under testdata generated in the generic mode.
+var genericIDLs = map[string]bool{
+	"generic.thrift": true,
+}
+
+// httpIDLs are the IDLs under testdata generated with the http protocol.
+var httpIDLs = map[string]bool{
+	"http.thrift": true,
+}
+
+// serverIDLs are the IDLs under testdata generated with a server.
+var serverIDLs = map[string]bool{
+	"all_types.thrift": true,
+	"streaming.thrift": true,
+}
+
+func TestRenderClientTemplates(t *testing.T) {
+	renders := map[string]func(*config.RGOClientTemplateData) (string, error){
+		"edit":  RenderEditClientTemplate,
+		"build": RenderCompileClientTemplate,
+		"mock":  RenderMockClientTemplate,
+	}
+	serverRenders := map[string]func(*config.RGOClientTemplateData) (string, error){
+		"server_edit":  RenderEditServerTemplate,
+		"server_build": RenderCompileServerTemplate,
+	}
+
+	idls, err := filepath.Glob(filepath.Join("testdata", "*.thrift"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, idl := range idls {
+		ast, err := parser.ParseFile(idl, nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ast.Services) == 0 {
+			continue
+		}
+
+		rgoPlugin := &RGOPlugin{
+			ProjectModule: "rgo/test_service",
+			Client:        testClients[filepath.Base(idl)],
+			Middlewares:   testMiddlewares[filepath.Base(idl)],
+		}
+		if genericIDLs[filepath.Base(idl)] {
+			rgoPlugin.Generic = true
+			rgoPlugin.IDLPath = "/rgo/idl/" + filepath.Base(idl)
+		}
+		rgoPlugin.HTTP = httpIDLs[filepath.Base(idl)]
+
+		data, err := rgoPlugin.buildClientTemplateData("test.service", "test_service", ast, kitexThriftOptions)
+		if err != nil {
+			t.Fatalf("%s: %v", idl, err)
+		}
+
+		periods := renders
+		if serverIDLs[filepath.Base(idl)] {
+			periods = map[string]func(*config.RGOClientTemplateData) (string, error){}
+			for period, render := range renders {
+				periods[period] = render
+			}
+			for period, render := range serverRenders {
+				periods[period] = render
+			}
+		}
+
+		files := map[string][]byte{}
+		for period, render := range periods {
+			if period == "build" && rgoPlugin.Generic {
+				render = RenderGenericClientTemplate
+			}
+			if period == "build" && rgoPlugin.HTTP {
+				render = RenderHTTPClientTemplate
+			}
+
+			rendered, err := render(data)
+			if err != nil {
+				t.Fatalf("%s: failed to render %s template: %v", idl, period, err)
+			}
+
+			code, err := format.Source([]byte(rendered))
+			if err != nil {
+				t.Fatalf("%s: %s template renders invalid go code: %v\n%s", idl, period, err, rendered)
+			}
+
+			files[period] = code
+
+			golden := filepath.Join("testdata", fmt.Sprintf("%s_%s.golden", strings.TrimSuffix(filepath.Base(idl), ".thrift"), period))
+
+			if *update {
+				if err = os.WriteFile(golden, code, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(want) != string(code) {
+				t.Errorf("%s: %s template differs from %s, run go test with -update to refresh it\ngot:\n%s", idl, period, golden, code)
+			}
+		}
+
+		// The edit-period and build-period files each form a package
+		// together with the mock and the server of the same period.
+		for _, pkg := range [][]string{{"edit", "mock", "server_edit"}, {"build", "mock", "server_build"}} {
+			sources := map[string][]byte{}
+			for _, period := range pkg {
+				if code, ok := files[period]; ok {
+					sources[period+".go"] = code
+				}
+			}
+
+			if err = typeCheck(rgoPlugin.ProjectModule, sources); err != nil {
+				t.Errorf("%s: %s templates render code that does not compile: %v", idl, pkg[0], err)
+			}
+		}
+	}
+}
+
+// typeCheck type-checks sources, keyed by file name, as one package. Imports outside the standard
+// library resolve to empty stub packages, so only references into them and
+// values of their types are left unchecked; unused imports, undeclared
+// identifiers and mismatches inside the generated code are still reported.
+// As selecting from a value of a stubbed type isn't checked, references to
+// an imported package shadowed by a parameter are reported separately.
+func typeCheck(projectModule string, sources map[string][]byte) error {
+	fset := token.NewFileSet()
+	imp := &stubImporter{projectModule: projectModule, std: importer.Default(), stubs: map[string]*types.Package{}}
+
+	var files []*ast.File
+	stubNames := map[string]bool{}
+	importNames := map[*ast.File]map[string]bool{}
+	for name, src := range sources {
+		f, err := goparser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+
+		importNames[f] = map[string]bool{}
+		for _, spec := range f.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			pkgName := stubPackageName(path)
+			if spec.Name != nil {
+				pkgName = spec.Name.Name
+			}
+			importNames[f][pkgName] = true
+			if imp.isStub(path) {
+				stubNames[pkgName] = true
+			}
+		}
+	}
+
+	var errs []string
+	conf := types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			msg := err.(types.Error).Msg
+			if strings.HasPrefix(msg, "undefined: ") {
+				if name, _, ok := strings.Cut(strings.TrimPrefix(msg, "undefined: "), "."); ok && stubNames[name] {
+					return
+				}
+			}
+			// Values of stubbed types have an invalid type.
+			if strings.Contains(msg, "unknown type") || strings.Contains(msg, "invalid type") {
+				return
+			}
+			errs = append(errs, err.Error())
+		},
+	}
+	info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+	_, _ = conf.Check("test_service", fset, files, info)
+
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			x, ok := sel.X.(*ast.Ident)
+			if !ok || !importNames[f][x.Name] {
+				return true
+			}
+			if obj := info.Uses[x]; obj != nil {
+				if _, ok = obj.(*types.PkgName); !ok {
+					errs = append(errs, fmt.Sprintf("%s: %s shadows the imported package", fset.Position(x.Pos()), x.Name))
+				}
+			}
+			return true
+		})
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// stubImporter imports the standard library and stubs every other package.
+type stubImporter struct {
+	projectModule string
+	std           types.Importer
+	stubs         map[string]*types.Package
+}
+
+func (i *stubImporter) isStub(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return strings.Contains(first, ".") || strings.HasPrefix(path, i.projectModule+"/")
+}
+
+func (i *stubImporter) Import(path string) (*types.Package, error) {
+	if !i.isStub(path) {
+		return i.std.Import(path)
+	}
+
+	pkg, ok := i.stubs[path]
+	if !ok {
+		pkg = types.NewPackage(path, stubPackageName(path))
+		pkg.MarkComplete()
+		i.stubs[path] = pkg
+	}
+	return pkg, nil
+}
+
+// stubPackageName returns the conventional name of the package at path,
+// skipping a major version suffix.
+func stubPackageName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	return name
+}
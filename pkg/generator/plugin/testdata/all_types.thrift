@@ -0,0 +1,43 @@
+namespace java com.example.all
+namespace go example.all
+
+include "base.thrift"
+
+typedef i64 UserID
+typedef Request Req
+enum Color { RED, GREEN }
+struct Request { 1: string message, 2: base.Base base }
+struct Response { 1: string message }
+exception NotFound { 1: string id }
+
+/**
+ * AllTypes takes and returns every kind of type.
+ * Unlike ping(), echo(req) has a response.
+ */
+service AllTypes {
+    // echo returns the request it receives.
+    Response echo(
+        // req is returned as is.
+        1: Request req
+    )
+    # ping checks that the service is up.
+    void ping()
+    oneway void fire(1: Request req)
+    bool b(1: bool v)
+    byte y(1: byte v)
+    i8 i8v(1: i8 v)
+    i16 i16v(1: i16 v)
+    i32 i32v(1: i32 v)
+    i64 i64v(1: i64 v)
+    double d(1: double v)
+    string s(1: string v)
+    binary bin(1: binary v)
+    list<Request> lst(1: list<string> v)
+    set<i32> st(1: set<Request> v)
+    map<string, Response> mp(1: map<i64, list<Request>> v)
+    Color enm(1: Color v)
+    UserID tdef(1: UserID id, 2: Req r)
+    base.Status inc(1: base.Base b)
+    Response getUserInfo(1: i64 id) throws (1: NotFound nf)
+    void remove(1: i64 id) throws (1: NotFound nf, 2: base.NotFound bnf)
+}
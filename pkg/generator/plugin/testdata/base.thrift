@@ -0,0 +1,4 @@
+namespace go base
+struct Base { 1: string caller }
+enum Status { OK = 0, FAIL = 1 }
+exception NotFound { 1: string id }
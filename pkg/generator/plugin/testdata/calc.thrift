@@ -0,0 +1,6 @@
+namespace go calc
+
+// Calc only uses base types, so the client references no kitex_gen type.
+service Calc {
+    i64 add(1: i64 a, 2: i64 b)
+}
@@ -0,0 +1,15 @@
+namespace java com.example.clash
+namespace py example.clash
+namespace go client
+
+include "time.thrift"
+include "base.thrift"
+
+struct Request {
+    1: time.Duration timeout
+    2: optional base.Base base
+}
+
+service Client {
+    time.Duration Get(1: Request req, 2: map<string, time.Duration> timeouts) throws (1: base.NotFound notFound)
+}
@@ -0,0 +1,22 @@
+namespace go example.generic
+
+include "base.thrift"
+
+struct Request {
+    1: string message
+    2: optional base.Base base
+}
+
+struct Response {
+    1: string message
+    2: base.Status status
+}
+
+// Generic is called with JSON requests.
+service Generic {
+    /* Echo returns the message of req. */
+    Response Echo(1: Request req)
+    i64 Count(1: string text) throws (1: base.NotFound notFound)
+    void Notify(1: Request req)
+    oneway void Fire(1: Request req)
+}
@@ -0,0 +1,41 @@
+namespace go user
+
+include "base.thrift"
+
+struct GetUserRequest {
+    1: i64 id (api.path="id")
+    2: optional string fields (api.query="fields")
+    3: list<base.Status> statuses (api.query="status")
+    4: string token (api.header="Authorization")
+}
+
+struct CreateUserRequest {
+    1: string name
+    2: string org (api.path="org")
+    3: optional i32 age
+}
+
+struct User {
+    1: i64 id
+    2: string name
+    3: base.Status status
+}
+
+struct FindUserRequest {
+    1: string name (api.query="name")
+    2: string trace (api.header="X-Trace")
+}
+
+struct DeleteUserRequest {
+    1: i64 id (api.path="id")
+}
+
+// UserService is served by Hertz.
+service UserService {
+    // getUser returns the user with the given id.
+    User getUser(1: GetUserRequest v) (api.get="/users/:id")
+    User createUser(1: CreateUserRequest url) (api.post="/orgs/{org}/users")
+    void deleteUser(1: DeleteUserRequest http) throws (1: base.NotFound notFound) (api.delete="/users/:id")
+    User findUser(1: FindUserRequest query) (api.get="/users")
+    User me() (api.get="/me")
+}
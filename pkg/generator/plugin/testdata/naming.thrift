@@ -0,0 +1,21 @@
+namespace go naming
+
+include "base.thrift"
+
+struct Request {
+    1: string message
+}
+
+exception NotFound {
+    1: string id
+}
+
+service Naming {
+    Request getUserInfo(1: i64 userID)
+    Request SetDefaultClient(1: Request req)
+    Request client(1: Request c, 2: string opts)
+    Request NotFound(1: Request stream, 2: string type, 3: string fmt)
+    void ping(1: string r, 2: string m) throws (1: NotFound notFound)
+    void ExpectPing()
+    void pong() throws (1: base.NotFound notFound)
+}
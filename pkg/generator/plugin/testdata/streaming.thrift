@@ -0,0 +1,21 @@
+namespace go echo
+
+include "base.thrift"
+
+struct EchoRequest {
+    1: string message
+    2: optional base.Base base
+}
+
+struct EchoResponse {
+    1: string message
+}
+
+service Echo {
+    // Bidi echoes each request of the stream.
+    EchoResponse Bidi(1: EchoRequest req) (streaming.mode="bidirectional")
+    EchoResponse Upload(1: EchoRequest req) (streaming.mode="client")
+    EchoResponse Download(1: EchoRequest req) (streaming.mode="server")
+    EchoResponse Unary(1: EchoRequest req) (streaming.mode="unary")
+    EchoResponse Ping(1: EchoRequest req) throws (1: base.NotFound notFound)
+}
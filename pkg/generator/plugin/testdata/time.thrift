@@ -0,0 +1,5 @@
+namespace java com.example.time
+
+struct Duration {
+    1: i64 nanos
+}
@@ -23,8 +23,6 @@ import (
 
 	"github.com/cloudwego-contrib/rgo/pkg/config"
 	"github.com/cloudwego/thriftgo/parser"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 
 	"github.com/cloudwego-contrib/rgo/pkg/consts"
 	"github.com/cloudwego-contrib/rgo/pkg/utils"
@@ -63,15 +61,7 @@ func (r *RGOThriftgoPlugin) Invoke(req *plugin.Request) (res *plugin.Response) {
 	formatServiceName := r.FormatServiceName
 	serviceName := r.ServiceName
 
-	thrift := req.AST
-
-	for k := range thrift.Services {
-		for i := range thrift.Services[k].Functions {
-			thrift.Services[k].Functions[i].Name = cases.Title(language.Und).String(thrift.Services[k].Functions[i].Name)
-		}
-	}
-
-	templateData, err := r.buildClientTemplateData(serviceName, formatServiceName, thrift)
+	templateData, err := r.buildClientTemplateData(serviceName, formatServiceName, req.AST, req.GeneratorParameters)
 	if err != nil {
 		return &plugin.Response{
 			Error: strToPointer(fmt.Sprintf("failed to build client template data: %v", err)),
@@ -134,14 +124,6 @@ func (r *RGOThriftgoPlugin) Invoke(req *plugin.Request) (res *plugin.Response) {
 	return &plugin.Response{}
 }
 
-func (r *RGOThriftgoPlugin) buildClientTemplateData(serviceName, formatServiceName string, thriftFile *parser.Thrift) (*config.RGOClientTemplateData, error) {
-	data := &config.RGOClientTemplateData{
-		RGOModuleName:     r.ProjectModule,
-		ServiceName:       serviceName,
-		FormatServiceName: formatServiceName,
-		Imports:           []string{"context", "github.com/cloudwego/kitex/client", "github.com/cloudwego/kitex/client/callopt"},
-		Thrift:            thriftFile,
-	}
-
-	return data, nil
+func (r *RGOThriftgoPlugin) buildClientTemplateData(serviceName, formatServiceName string, thriftFile *parser.Thrift, generatorParameters []string) (*config.RGOClientTemplateData, error) {
+	return NewClientTemplateData(r.ProjectModule, serviceName, formatServiceName, thriftFile, generatorParameters)
 }
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+)
+
+// GenerationReport collects the outcome of a generation run so it can be
+// surfaced once at the end instead of being scattered across the log.
+type GenerationReport struct {
+	mu           sync.Mutex
+	Services     []ServiceResult
+	HookFailures []HookFailure
+}
+
+type ServiceResult struct {
+	ServiceName string
+	OutputDir   string
+	Err         error
+}
+
+type HookFailure struct {
+	Stage       string
+	ServiceName string
+	Hook        string
+	Err         error
+}
+
+func NewGenerationReport() *GenerationReport {
+	return &GenerationReport{}
+}
+
+func (r *GenerationReport) AddServiceResult(serviceName, outputDir string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Services = append(r.Services, ServiceResult{
+		ServiceName: serviceName,
+		OutputDir:   outputDir,
+		Err:         err,
+	})
+}
+
+func (r *GenerationReport) AddHookFailure(hc HookContext, hook config.Hook, err error) {
+	name := hook.Name
+	if name == "" {
+		name = hook.Command
+	}
+	if name == "" {
+		name = hook.Plugin
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.HookFailures = append(r.HookFailures, HookFailure{
+		Stage:       hc.Stage,
+		ServiceName: hc.ServiceName,
+		Hook:        name,
+		Err:         err,
+	})
+}
+
+// Err returns a single error describing every failure in the report, or nil
+// if the run was clean.
+func (r *GenerationReport) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var msgs []string
+
+	for _, s := range r.Services {
+		if s.Err != nil {
+			msgs = append(msgs, fmt.Sprintf("service %s: %v", s.ServiceName, s.Err))
+		}
+	}
+
+	for _, f := range r.HookFailures {
+		if f.ServiceName != "" {
+			msgs = append(msgs, fmt.Sprintf("%s hook %s for service %s: %v", f.Stage, f.Hook, f.ServiceName, f.Err))
+		} else {
+			msgs = append(msgs, fmt.Sprintf("%s hook %s: %v", f.Stage, f.Hook, f.Err))
+		}
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(msgs, "; "))
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+)
+
+func TestGenerationReport(t *testing.T) {
+	report := NewGenerationReport()
+
+	report.AddServiceResult("echo", "/rgo/echo", nil)
+	if err := report.Err(); err != nil {
+		t.Fatalf("Err() of a clean run = %v", err)
+	}
+
+	report.AddServiceResult("calc", "/rgo/calc", errors.New("kitex failed"))
+	report.AddHookFailure(HookContext{Stage: consts.HookStagePostService, ServiceName: "echo"}, config.Hook{Name: "lint", Command: "golangci-lint run"}, errors.New("exit status 1"))
+	report.AddHookFailure(HookContext{Stage: consts.HookStagePostRun}, config.Hook{Command: "make fmt"}, errors.New("exit status 2"))
+	report.AddHookFailure(HookContext{Stage: consts.HookStagePreFetch}, config.Hook{Plugin: "auth"}, errors.New("no token"))
+
+	if len(report.Services) != 2 || report.Services[1].OutputDir != "/rgo/calc" {
+		t.Errorf("services = %+v", report.Services)
+	}
+
+	want := "service calc: kitex failed; " +
+		"post_service hook lint for service echo: exit status 1; " +
+		"post_run hook make fmt: exit status 2; " +
+		"pre_fetch hook auth: no token"
+	if err := report.Err(); err == nil || err.Error() != want {
+		t.Errorf("Err() = %v, want %s", err, want)
+	}
+}
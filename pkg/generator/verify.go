@@ -0,0 +1,81 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cloudwego-contrib/rgo/pkg/config"
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"github.com/cloudwego-contrib/rgo/pkg/generator/plugin"
+	"github.com/cloudwego-contrib/rgo/pkg/utils"
+)
+
+// APIResult is the comparison of the edit-period and build-period clients of
+// a service. Diff is empty when their exported APIs are the same.
+type APIResult struct {
+	ServiceName string
+	EditPath    string
+	BuildPath   string
+	Diff        []string
+}
+
+// VerifyAPI compares the exported API of the edit-period client of each IDL
+// of c, which the IDE type-checks against, with the one of its build-period
+// client, which gets compiled. Both must have been generated.
+func VerifyAPI(rgoBasePath string, c *config.RGOConfig) ([]APIResult, error) {
+	commits := make(map[string]string, len(c.IDLRepos))
+	for _, repo := range c.IDLRepos {
+		commits[repo.RepoName] = repo.Commit
+	}
+
+	var results []APIResult
+
+	for _, idl := range c.IDLs {
+		result := APIResult{
+			ServiceName: idl.ServiceName,
+			EditPath:    filepath.Join(rgoBasePath, consts.RepoPath, idl.FormatServiceName),
+			BuildPath:   filepath.Join(rgoBasePath, consts.BuildPath, idl.RepoName, commits[idl.RepoName], idl.FormatServiceName),
+		}
+
+		for _, path := range []string{result.EditPath, result.BuildPath} {
+			exist, err := utils.FileExistsInPath(path, consts.GoMod)
+			if err != nil {
+				return nil, err
+			}
+			if !exist {
+				return nil, fmt.Errorf("no client of %s generated in %s, run rgo generate first", idl.ServiceName, path)
+			}
+		}
+
+		edit, err := plugin.LoadAPI(result.EditPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load edit-period client of %s: %v", idl.ServiceName, err)
+		}
+
+		build, err := plugin.LoadAPI(result.BuildPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load build-period client of %s: %v", idl.ServiceName, err)
+		}
+
+		result.Diff = plugin.DiffAPI(edit, build)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
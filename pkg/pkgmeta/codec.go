@@ -0,0 +1,193 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkgmeta
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var errTruncated = errors.New("truncated data")
+
+// encoder writes uvarints and length-prefixed strings.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.uvarint(1)
+	} else {
+		e.uvarint(0)
+	}
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) strings(ss []string) {
+	e.uvarint(uint64(len(ss)))
+	for _, s := range ss {
+		e.string(s)
+	}
+}
+
+// files writes the paths of files in dir relative to it, which is most of
+// them and keeps the metadata small.
+func (e *encoder) files(dir string, files []string) {
+	e.uvarint(uint64(len(files)))
+	for _, f := range files {
+		if dir != "" && filepath.Dir(f) == dir {
+			f = filepath.Base(f)
+		}
+		e.string(f)
+	}
+}
+
+// imports writes the IDs of imports sorted by path, for reproducible files.
+func (e *encoder) imports(imports map[string]string) {
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	e.uvarint(uint64(len(paths)))
+	for _, path := range paths {
+		e.string(path)
+		e.string(imports[path])
+	}
+}
+
+func (e *encoder) module(m *packages.Module) {
+	e.bool(m != nil)
+	if m == nil {
+		return
+	}
+	e.string(m.Path)
+	e.string(m.Version)
+	e.string(m.Dir)
+	e.string(m.GoMod)
+	e.string(m.GoVersion)
+	e.bool(m.Main)
+	e.bool(m.Indirect)
+	e.module(m.Replace)
+}
+
+// decoder reads what encoder wrote, keeping the first error.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errTruncated
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// count reads a length, at most the bytes left since every element takes one.
+func (d *decoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.err = errTruncated
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bool() bool {
+	return d.uvarint() != 0
+}
+
+func (d *decoder) string() string {
+	n := d.count()
+	if d.err != nil {
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *decoder) strings() []string {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	ss := make([]string, n)
+	for i := range ss {
+		ss[i] = d.string()
+	}
+	return ss
+}
+
+func (d *decoder) files(dir string) []string {
+	files := d.strings()
+	for i, f := range files {
+		if !filepath.IsAbs(f) && dir != "" {
+			files[i] = filepath.Join(dir, f)
+		}
+	}
+	return files
+}
+
+func (d *decoder) imports() map[string]string {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	imports := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		path := d.string()
+		imports[path] = d.string()
+	}
+	return imports
+}
+
+func (d *decoder) module() *packages.Module {
+	if !d.bool() {
+		return nil
+	}
+	return &packages.Module{
+		Path:      d.string(),
+		Version:   d.string(),
+		Dir:       d.string(),
+		GoMod:     d.string(),
+		GoVersion: d.string(),
+		Main:      d.bool(),
+		Indirect:  d.bool(),
+		Replace:   d.module(),
+	}
+}
@@ -0,0 +1,365 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package pkgmeta reads and writes the metadata of the packages of generated
+// modules, which rgopackagesdriver answers gopls with.
+//
+// A metadata file starts with a magic string and a JSON header telling which
+// rgo and x/tools wrote it, from which commit and configuration. An index
+// follows, holding what matching patterns and following imports need, then
+// the records of the packages, decoded only when a package is returned.
+package pkgmeta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"golang.org/x/tools/go/packages"
+)
+
+// FormatVersion is the version of the format Write writes, the only one Read
+// reads. Records are encoded by rgo rather than x/tools, so only changes of
+// rgo bump it.
+const FormatVersion = 2
+
+const magic = "RGOMETA\n"
+
+type Header struct {
+	FormatVersion int    `json:"format_version"`
+	RGOVersion    string `json:"rgo_version"`
+	ToolsVersion  string `json:"tools_version"`
+	// Commit is the commit of the IDL repository the module was generated from.
+	Commit string `json:"commit"`
+	// ConfigHash is config.RGOConfig.IDLHash of the IDL of the module.
+	ConfigHash string `json:"config_hash"`
+}
+
+// IncompatibleError reports a metadata file Read can't read, which has to be
+// generated again.
+type IncompatibleError struct {
+	Path string
+	// Header is nil for files of the JSON format of earlier versions.
+	Header *Header
+}
+
+func (e *IncompatibleError) Error() string {
+	const regenerate = "run rgo generate or restart rgo_lsp_server to generate it again"
+	if e.Header == nil {
+		return fmt.Sprintf("%s isn't in the metadata format %d of rgo %s, %s", e.Path, FormatVersion, consts.RGOVersion, regenerate)
+	}
+	return fmt.Sprintf("%s is in the metadata format %d of rgo %s, not %d of rgo %s, %s",
+		e.Path, e.Header.FormatVersion, e.Header.RGOVersion, FormatVersion, consts.RGOVersion, regenerate)
+}
+
+// Entry is a package of a metadata file, with the fields needed before the
+// package is returned. Package decodes the others.
+type Entry struct {
+	ID      string
+	Name    string
+	PkgPath string
+	// Dir is the directory of the Go files of the package.
+	Dir string
+	// ForTest is the path of the package under test of a test variant, "q"
+	// of "p [q.test]", and "" for other packages.
+	ForTest string
+	// Imports maps the import paths of the package to the IDs of the packages.
+	Imports map[string]string
+	Module  *packages.Module
+
+	data []byte
+	once sync.Once
+	pkg  *packages.Package
+	err  error
+}
+
+// Package returns the package of e, decoding it on the first call.
+func (e *Entry) Package() (*packages.Package, error) {
+	e.once.Do(func() {
+		pkg := &packages.Package{ID: e.ID, Name: e.Name, PkgPath: e.PkgPath, Module: e.Module}
+		if len(e.Imports) > 0 {
+			pkg.Imports = make(map[string]*packages.Package, len(e.Imports))
+			for path, id := range e.Imports {
+				pkg.Imports[path] = &packages.Package{ID: id}
+			}
+		}
+
+		d := &decoder{data: e.data}
+		if n := d.count(); n > 0 {
+			pkg.Errors = make([]packages.Error, n)
+			for i := range pkg.Errors {
+				pkg.Errors[i] = packages.Error{Pos: d.string(), Msg: d.string(), Kind: packages.ErrorKind(d.uvarint())}
+			}
+		}
+		pkg.GoFiles = d.files(e.Dir)
+		pkg.CompiledGoFiles = d.files(e.Dir)
+		pkg.OtherFiles = d.files(e.Dir)
+		pkg.EmbedFiles = d.files(e.Dir)
+		pkg.EmbedPatterns = d.strings()
+		pkg.IgnoredFiles = d.files(e.Dir)
+		pkg.ExportFile = d.string()
+
+		if d.err != nil {
+			e.err = fmt.Errorf("failed to decode package %s: %v", e.ID, d.err)
+			return
+		}
+		e.pkg = pkg
+	})
+	return e.pkg, e.err
+}
+
+type File struct {
+	Header  Header
+	Entries []*Entry
+}
+
+// ReadHeader reads the header of the metadata file at path.
+func ReadHeader(path string) (*Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readHeader(path, bufio.NewReader(f))
+}
+
+// Read reads the header and the index of the metadata file at path.
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bytes.NewReader(data)
+	header, err := readHeader(path, r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{data: data[len(data)-r.Len():]}
+	n := d.count()
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to read index of %s: %v", path, d.err)
+	}
+	index, payload := &decoder{data: d.data[:n]}, d.data[n:]
+
+	modules := make([]*packages.Module, index.count())
+	for i := range modules {
+		modules[i] = index.module()
+	}
+
+	file := &File{Header: *header, Entries: make([]*Entry, index.count())}
+	for i := range file.Entries {
+		e := &Entry{
+			ID:      index.string(),
+			Name:    index.string(),
+			PkgPath: index.string(),
+			Dir:     index.string(),
+			ForTest: index.string(),
+			Imports: index.imports(),
+		}
+		if m := index.uvarint(); m > 0 && m <= uint64(len(modules)) {
+			e.Module = modules[m-1]
+		}
+		off, size := index.uvarint(), index.uvarint()
+		if off > uint64(len(payload)) || size > uint64(len(payload))-off {
+			return nil, fmt.Errorf("failed to read index of %s: record of %s out of range", path, e.ID)
+		}
+		e.data = payload[off : off+size]
+		file.Entries[i] = e
+	}
+	if index.err != nil {
+		return nil, fmt.Errorf("failed to read index of %s: %v", path, index.err)
+	}
+
+	return file, nil
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func readHeader(path string, r byteReader) (*Header, error) {
+	prefix := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, prefix); err != nil || string(prefix) != magic {
+		return nil, &IncompatibleError{Path: path}
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > 1<<20 {
+		return nil, fmt.Errorf("failed to read header of %s: invalid length", path)
+	}
+	data := make([]byte, n)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read header of %s: %v", path, err)
+	}
+
+	header := &Header{}
+	if err = json.Unmarshal(data, header); err != nil {
+		return nil, fmt.Errorf("failed to parse header of %s: %v", path, err)
+	}
+	if header.FormatVersion != FormatVersion {
+		return nil, &IncompatibleError{Path: path, Header: header}
+	}
+
+	return header, nil
+}
+
+// Write writes the metadata of pkgs to path with header, whose versions it
+// sets. The file is replaced at once, so readers never see part of it.
+func Write(path string, header Header, pkgs []*packages.Package) error {
+	header.FormatVersion = FormatVersion
+	header.RGOVersion = consts.RGOVersion
+	header.ToolsVersion = ToolsVersion()
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal header: %v", err)
+	}
+
+	var (
+		index, payload encoder
+		modules        []*packages.Module
+		moduleIndex    = map[packages.Module]int{}
+	)
+	for _, pkg := range pkgs {
+		if m := pkg.Module; m != nil {
+			if _, ok := moduleIndex[*m]; !ok {
+				modules = append(modules, m)
+				moduleIndex[*m] = len(modules)
+			}
+		}
+	}
+	index.uvarint(uint64(len(modules)))
+	for _, m := range modules {
+		index.module(m)
+	}
+
+	index.uvarint(uint64(len(pkgs)))
+	for _, pkg := range pkgs {
+		dir := packageDir(pkg)
+		imports := make(map[string]string, len(pkg.Imports))
+		for path, imp := range pkg.Imports {
+			imports[path] = imp.ID
+		}
+
+		index.string(pkg.ID)
+		index.string(pkg.Name)
+		index.string(pkg.PkgPath)
+		index.string(dir)
+		index.string(ForTest(pkg.ID))
+		index.imports(imports)
+		if pkg.Module != nil {
+			index.uvarint(uint64(moduleIndex[*pkg.Module]))
+		} else {
+			index.uvarint(0)
+		}
+
+		start := len(payload.buf)
+		payload.uvarint(uint64(len(pkg.Errors)))
+		for _, e := range pkg.Errors {
+			payload.string(e.Pos)
+			payload.string(e.Msg)
+			payload.uvarint(uint64(e.Kind))
+		}
+		payload.files(dir, pkg.GoFiles)
+		payload.files(dir, pkg.CompiledGoFiles)
+		payload.files(dir, pkg.OtherFiles)
+		payload.files(dir, pkg.EmbedFiles)
+		payload.strings(pkg.EmbedPatterns)
+		payload.files(dir, pkg.IgnoredFiles)
+		payload.string(pkg.ExportFile)
+
+		index.uvarint(uint64(start))
+		index.uvarint(uint64(len(payload.buf) - start))
+	}
+
+	out := &encoder{buf: []byte(magic)}
+	out.string(string(data))
+	out.uvarint(uint64(len(index.buf)))
+	out.buf = append(out.buf, index.buf...)
+	out.buf = append(out.buf, payload.buf...)
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directories: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(out.buf)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", tmp.Name(), err)
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to chmod %s: %v", tmp.Name(), err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// ForTest returns the path of the package under test of the test variant
+// with id, which x/tools only keeps unexported, or "" if id isn't one.
+func ForTest(id string) string {
+	i := strings.Index(id, " [")
+	if i < 0 || !strings.HasSuffix(id, ".test]") {
+		return ""
+	}
+	return strings.TrimSuffix(id[i+len(" ["):], ".test]")
+}
+
+// packageDir returns the directory of the Go files of pkg.
+func packageDir(pkg *packages.Package) string {
+	for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles} {
+		if len(files) > 0 {
+			return filepath.Dir(files[0])
+		}
+	}
+	return ""
+}
+
+// ToolsVersion returns the version of x/tools rgo is built with, which Write
+// records in headers.
+func ToolsVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "golang.org/x/tools" {
+				if dep.Replace != nil {
+					return dep.Replace.Version
+				}
+				return dep.Version
+			}
+		}
+	}
+	return "unknown"
+}
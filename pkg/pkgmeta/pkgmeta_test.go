@@ -0,0 +1,126 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkgmeta
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestWriteRead(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "repo", "echo")
+	module := &packages.Module{Path: "rgo/echo", Dir: dir, GoMod: filepath.Join(dir, "go.mod"), GoVersion: "1.18", Main: true}
+
+	pkgs := []*packages.Package{
+		{
+			ID:              "rgo/echo",
+			Name:            "echo",
+			PkgPath:         "rgo/echo",
+			GoFiles:         []string{filepath.Join(dir, "client.go")},
+			CompiledGoFiles: []string{filepath.Join(dir, "client.go"), "/cache/x.go"},
+			EmbedPatterns:   []string{"idl/*"},
+			EmbedFiles:      []string{filepath.Join(dir, "idl", "echo.thrift")},
+			Imports: map[string]*packages.Package{
+				"context":                 {ID: "context"},
+				"rgo/echo/kitex_gen/echo": {ID: "rgo/echo/kitex_gen/echo"},
+			},
+			Module: module,
+		},
+		{
+			ID:      "rgo/echo/kitex_gen/echo",
+			Name:    "echo",
+			PkgPath: "rgo/echo/kitex_gen/echo",
+			Errors:  []packages.Error{{Pos: "a.go:1:1", Msg: "boom", Kind: packages.TypeError}},
+			Module:  module,
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), consts.PkgMetaFile)
+	if err := Write(path, Header{Commit: "abc", ConfigHash: "123"}, pkgs); err != nil {
+		t.Fatal(err)
+	}
+
+	header, err := ReadHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.FormatVersion != FormatVersion || header.RGOVersion != consts.RGOVersion || header.Commit != "abc" || header.ConfigHash != "123" {
+		t.Errorf("got header %+v", header)
+	}
+
+	file, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Entries) != len(pkgs) {
+		t.Fatalf("got %d entries, want %d", len(file.Entries), len(pkgs))
+	}
+	if e := file.Entries[0]; e.Dir != dir || e.Imports["rgo/echo/kitex_gen/echo"] != "rgo/echo/kitex_gen/echo" || e.Module.Path != "rgo/echo" {
+		t.Errorf("got entry %+v", e)
+	}
+
+	for i, e := range file.Entries {
+		got, err := e.Package()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Compare the JSON forms, as gopls gets them, and the modules.
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(pkgs[i])
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("got package %s, want %s", gotJSON, wantJSON)
+		}
+		if !reflect.DeepEqual(got.Module, module) {
+			t.Errorf("got module %+v, want %+v", got.Module, module)
+		}
+	}
+}
+
+func TestReadIncompatible(t *testing.T) {
+	dir := t.TempDir()
+
+	legacy := filepath.Join(dir, consts.LegacyPkgMetaFile)
+	if err := os.WriteFile(legacy, []byte(`[{"ID":"rgo/echo"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	header := []byte(`{"format_version":0,"rgo_version":"0.0.0"}`)
+	old := filepath.Join(dir, consts.PkgMetaFile)
+	data := binary.AppendUvarint([]byte(magic), uint64(len(header)))
+	if err := os.WriteFile(old, append(data, header...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, wantHeader := range map[string]bool{legacy: false, old: true} {
+		_, err := Read(path)
+		var incompatible *IncompatibleError
+		if !errors.As(err, &incompatible) {
+			t.Fatalf("Read(%s) = %v, want an IncompatibleError", path, err)
+		}
+		if (incompatible.Header != nil) != wantHeader {
+			t.Errorf("Read(%s) = %v, want header %v", path, err, wantHeader)
+		}
+	}
+}
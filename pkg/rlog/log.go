@@ -70,6 +70,15 @@ func InitLogger(logPath string, server *lsp.Server) {
 	}
 }
 
+// notify shows message in the editor, when logging for the LSP server.
+func notify(method, message string) {
+	if lspServer == nil {
+		return
+	}
+	str, _ := json.Marshal(notification{Message: message})
+	_ = lspServer.SendNotification(method, str)
+}
+
 func Debug(s string, fields ...zap.Field) {
 	logger.Info(s, fields...)
 }
@@ -79,20 +88,17 @@ func Info(s string, fields ...zap.Field) {
 }
 
 func Warn(s string, fields ...zap.Field) {
-	str, _ := json.Marshal(notification{Message: s})
-	_ = lspServer.SendNotification(consts.MethodRGOWindowShowWarn, str)
+	notify(consts.MethodRGOWindowShowWarn, s)
 	logger.Warn(s, fields...)
 }
 
 func Error(s string, fields ...zap.Field) {
-	str, _ := json.Marshal(notification{Message: s})
-	_ = lspServer.SendNotification(consts.MethodRGOWindowShowError, str)
+	notify(consts.MethodRGOWindowShowError, s)
 	logger.Error(s, fields...)
 }
 
 func Fatal(s string, fields ...zap.Field) {
-	str, _ := json.Marshal(notification{Message: s})
-	_ = lspServer.SendNotification(consts.MethodRGOWindowShowError, str)
+	notify(consts.MethodRGOWindowShowError, s)
 	logger.Fatal(s, fields...)
 }
 
@@ -105,19 +111,16 @@ func Infof(format string, args ...interface{}) {
 }
 
 func Warnf(format string, args ...interface{}) {
-	str, _ := json.Marshal(notification{Message: fmt.Sprintf(format, args...)})
-	_ = lspServer.SendNotification(consts.MethodRGOWindowShowWarn, str)
+	notify(consts.MethodRGOWindowShowWarn, fmt.Sprintf(format, args...))
 	logger.Sugar().Warnf(format, args...)
 }
 
 func Errorf(format string, args ...interface{}) {
-	str, _ := json.Marshal(notification{Message: fmt.Sprintf(format, args...)})
-	_ = lspServer.SendNotification(consts.MethodRGOWindowShowError, str)
+	notify(consts.MethodRGOWindowShowError, fmt.Sprintf(format, args...))
 	logger.Sugar().Errorf(format, args...)
 }
 
 func Fatalf(format string, args ...interface{}) {
-	str, _ := json.Marshal(notification{Message: fmt.Sprintf(format, args...)})
-	_ = lspServer.SendNotification(consts.MethodRGOWindowShowError, str)
+	notify(consts.MethodRGOWindowShowError, fmt.Sprintf(format, args...))
 	logger.Sugar().Fatalf(format, args...)
 }
@@ -93,3 +93,22 @@ func GetLatestCommitID(filePath string) (string, error) {
 
 	return strings.TrimSpace(string(out)), nil
 }
+
+// GetLatestCommitDate returns the committer date of the latest commit of the
+// repository at filePath, in ISO 8601.
+func GetLatestCommitDate(filePath string) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%cI")
+	cmd.Dir = absPath
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the latest commit date: %v", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -20,10 +20,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
 )
 
 // PathExist is used to judge whether the path exists in file system.
@@ -59,29 +62,97 @@ func FileExistsInPath(dir, filename string) (bool, error) {
 	return !info.IsDir(), nil
 }
 
+// DirSize returns the total size of the regular files under path.
+func DirSize(path string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to walk directory %s: %v", path, err)
+	}
+
+	return size, nil
+}
+
 func GetFileNameWithoutExt(filePath string) string {
 	base := filepath.Base(filePath)
 	nameWithoutExt := strings.TrimSuffix(base, filepath.Ext(base))
 	return nameWithoutExt
 }
 
+// FindProjectRoot returns the root of the project dir belongs to: the nearest
+// directory up from dir holding rgo_config.yaml, or else go.work, or else
+// go.mod. Without any of them, it's dir itself.
+func FindProjectRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, marker := range []string{consts.RGOConfigFile, consts.GoWork, consts.GoMod} {
+		for d := dir; ; {
+			exist, err := FileExistsInPath(d, marker)
+			if err != nil {
+				return "", err
+			}
+			if exist {
+				return d, nil
+			}
+
+			parent := filepath.Dir(d)
+			if parent == d {
+				break
+			}
+			d = parent
+		}
+	}
+
+	return dir, nil
+}
+
+// GetProjectRoot returns the root of the project of the working directory.
+func GetProjectRoot() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	return FindProjectRoot(wd)
+}
+
 func GetProjectHashPathWithUnderline() (string, error) {
-	currentPath, err := os.Getwd()
+	currentPath, err := GetProjectRoot()
 	if err != nil {
 		return "", err
 	}
 
-	currentPath = strings.TrimSpace(currentPath)
+	return ProjectHashPathWithUnderline(currentPath), nil
+}
+
+// ProjectHashPathWithUnderline returns the name of the cache directory of the
+// project at root.
+func ProjectHashPathWithUnderline(root string) string {
+	root = strings.TrimSpace(root)
 
-	projectName := filepath.Base(currentPath)
+	projectName := filepath.Base(root)
 
 	hasher := sha256.New()
-	hasher.Write([]byte(currentPath))
+	hasher.Write([]byte(root))
 	hash := hex.EncodeToString(hasher.Sum(nil))
 
-	hashedPath := fmt.Sprintf("%s_%s", projectName, hash)
-
-	return hashedPath, nil
+	return fmt.Sprintf("%s_%s", projectName, hash)
 }
 
 func GetDefaultUserPath() string {
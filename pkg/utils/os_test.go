@@ -0,0 +1,66 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego-contrib/rgo/pkg/consts"
+)
+
+func TestFindProjectRoot(t *testing.T) {
+	tmp := t.TempDir()
+
+	mkdir := func(dir string, files ...string) string {
+		dir = filepath.Join(tmp, dir)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if err := os.WriteFile(filepath.Join(dir, f), nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+
+	project := mkdir("project", consts.RGOConfigFile, consts.GoWork)
+	mkdir("project/app", consts.GoMod)
+	work := mkdir("work", consts.GoWork)
+	mkdir("work/app/internal", consts.GoMod)
+	mod := mkdir("mod", consts.GoMod)
+	none := mkdir("none/pkg")
+
+	for dir, want := range map[string]string{
+		"project":              project,
+		"project/app":          project,
+		"work/app/internal":    work,
+		"mod":                  mod,
+		"none/pkg":             none,
+		"project/app/../app/.": project,
+	} {
+		got, err := FindProjectRoot(filepath.Join(tmp, dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("FindProjectRoot(%s) = %s, want %s", dir, got, want)
+		}
+	}
+}